@@ -0,0 +1,414 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/internal/yamlutil"
+	"github.com/xero-oss/go-xray/xray/reconcile"
+	"github.com/xero-oss/go-xray/xray/v1"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+var commands = map[string]command{
+	"watches list":     {usage: "list all watches", run: watchesList},
+	"watches get":      {usage: "get a watch: watches get NAME", run: watchesGet},
+	"watches apply":    {usage: "create or update watches: watches apply [-dry-run] -f FILE", run: watchesApply},
+	"policies list":    {usage: "list all policies", run: policiesList},
+	"policies get":     {usage: "get a policy: policies get NAME", run: policiesGet},
+	"policies apply":   {usage: "create or update policies: policies apply [-dry-run] -f FILE", run: policiesApply},
+	"scan build":       {usage: "scan a build: scan build -name NAME -number NUMBER", run: scanBuild},
+	"gate build":       {usage: "scan a build and exit with the gate outcome: gate build -name NAME -number NUMBER [-min-severity S] [-allow CVE]... [-max-alerts N]", run: gateBuild},
+	"summary artifact": {usage: "summarize artifacts: summary artifact [-path PATH]... [-checksum SHA256]...", run: summaryArtifact},
+	"violations list":  {usage: "list violations: violations list [-watch W] [-type T] [-min-severity S] [-limit N]", run: violationsList},
+	"reports license":  {usage: "get the last generated license report", run: reportsLicense},
+	"system ping":      {usage: "ping the Xray instance", run: systemPing},
+	"system version":   {usage: "get the Xray version", run: systemVersion},
+}
+
+// parseFlags parses the flags of a command, its usage is printed on error
+func parseFlags(flags *flag.FlagSet, e *env, args []string) error {
+	flags.SetOutput(e.stderr)
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+
+		return errUsage(err.Error())
+	}
+
+	return nil
+}
+
+func nameArg(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errUsage("expected a single NAME argument")
+	}
+
+	return args[0], nil
+}
+
+func watchesTable(watches []v2.Watch) func() *table {
+	return func() *table {
+		t := &table{headers: []string{"NAME", "ACTIVE", "RESOURCES", "POLICIES"}}
+		for _, w := range watches {
+			var name string
+			var active string
+			if w.GeneralData != nil {
+				name = ptr.StringValue(w.GeneralData.Name)
+				active = boolStr(w.GeneralData.Active)
+			}
+
+			var resources []string
+			if w.ProjectResources != nil && w.ProjectResources.Resources != nil {
+				for _, r := range *w.ProjectResources.Resources {
+					resource := ptr.StringValue(r.Type)
+					if r.Name != nil {
+						resource += ":" + *r.Name
+					}
+					resources = append(resources, resource)
+				}
+			}
+
+			var policies []string
+			if w.AssignedPolicies != nil {
+				for _, p := range *w.AssignedPolicies {
+					policies = append(policies, ptr.StringValue(p.Name))
+				}
+			}
+
+			t.add(name, active, strings.Join(resources, ","), strings.Join(policies, ","))
+		}
+		return t
+	}
+}
+
+func watchesList(e *env, args []string) error {
+	watches, _, err := e.client.V2.Watches.ListWatches(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(watches, watchesTable(*watches))
+}
+
+func watchesGet(e *env, args []string) error {
+	name, err := nameArg(args)
+	if err != nil {
+		return err
+	}
+
+	watch, _, err := e.client.V2.Watches.GetWatch(e.ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(watch, watchesTable([]v2.Watch{*watch}))
+}
+
+func policiesTable(policies []v1.Policy) func() *table {
+	return func() *table {
+		t := &table{headers: []string{"NAME", "TYPE", "RULES", "DESCRIPTION"}}
+		for _, p := range policies {
+			rules := 0
+			if p.Rules != nil {
+				rules = len(*p.Rules)
+			}
+			t.add(ptr.StringValue(p.Name), ptr.StringValue(p.Type), fmt.Sprintf("%d", rules), ptr.StringValue(p.Description))
+		}
+		return t
+	}
+}
+
+func policiesList(e *env, args []string) error {
+	policies, _, err := e.client.V1.Policies.ListPolicies(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(policies, policiesTable(*policies))
+}
+
+func policiesGet(e *env, args []string) error {
+	name, err := nameArg(args)
+	if err != nil {
+		return err
+	}
+
+	policy, _, err := e.client.V1.Policies.GetPolicy(e.ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(policy, policiesTable([]v1.Policy{*policy}))
+}
+
+// readObjects decodes a JSON or YAML file holding a single object or a list of objects into list
+func readObjects(path string, list interface{}, single func() interface{}, appendSingle func(interface{})) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := yamlutil.Unmarshal(data, list); err == nil {
+		return nil
+	}
+
+	v := single()
+	if err := yamlutil.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("reading %s: %s", path, err.Error())
+	}
+
+	appendSingle(v)
+	return nil
+}
+
+func watchesApply(e *env, args []string) error {
+	flags := flag.NewFlagSet("watches apply", flag.ContinueOnError)
+	file := flags.String("f", "", "JSON or YAML file with a watch or a list of watches")
+	dryRun := flags.Bool("dry-run", false, "only print the changes")
+	if err := parseFlags(flags, e, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errUsage("-f is required")
+	}
+
+	var watches []v2.Watch
+	err := readObjects(*file, &watches,
+		func() interface{} { return new(v2.Watch) },
+		func(v interface{}) { watches = append(watches, *v.(*v2.Watch)) })
+	if err != nil {
+		return err
+	}
+
+	return apply(e, &reconcile.State{Watches: watches}, *dryRun)
+}
+
+func policiesApply(e *env, args []string) error {
+	flags := flag.NewFlagSet("policies apply", flag.ContinueOnError)
+	file := flags.String("f", "", "JSON or YAML file with a policy or a list of policies")
+	dryRun := flags.Bool("dry-run", false, "only print the changes")
+	if err := parseFlags(flags, e, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errUsage("-f is required")
+	}
+
+	var policies []v1.Policy
+	err := readObjects(*file, &policies,
+		func() interface{} { return new(v1.Policy) },
+		func(v interface{}) { policies = append(policies, *v.(*v1.Policy)) })
+	if err != nil {
+		return err
+	}
+
+	return apply(e, &reconcile.State{Policies: policies}, *dryRun)
+}
+
+// apply creates or updates the desired objects, objects that are not part of the file are left untouched
+func apply(e *env, desired *reconcile.State, dryRun bool) error {
+	r := reconcile.New(e.client.V1.Policies, e.client.V2.Watches)
+
+	current, err := r.Current(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	plan, err := reconcile.ComputePlan(current, desired, false)
+	if err != nil {
+		return err
+	}
+
+	if plan.Empty() {
+		fmt.Fprintln(e.stdout, "No changes")
+		return nil
+	}
+
+	fmt.Fprint(e.stdout, plan.String())
+	if dryRun {
+		return nil
+	}
+
+	_, err = r.Apply(e.ctx, plan)
+	return err
+}
+
+func scanBuild(e *env, args []string) error {
+	flags := flag.NewFlagSet("scan build", flag.ContinueOnError)
+	name := flags.String("name", "", "build name")
+	number := flags.String("number", "", "build number")
+	artifactoryId := flags.String("artifactory-id", "", "id of the Artifactory instance holding the build")
+	if err := parseFlags(flags, e, args); err != nil {
+		return err
+	}
+	if *name == "" || *number == "" {
+		return errUsage("-name and -number are required")
+	}
+
+	input := v1.ScanBuildInput{BuildName: name, BuildNumber: number}
+	if *artifactoryId != "" {
+		input.ArtifactoryId = artifactoryId
+	}
+
+	output, _, err := e.client.V1.Scanning.ScanBuild(e.ctx, &input)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(output, func() *table {
+		t := &table{headers: []string{"WATCH", "SEVERITY", "TYPE", "ID", "SUMMARY"}}
+		if output.Alerts != nil {
+			for _, a := range *output.Alerts {
+				if a.Issues == nil {
+					continue
+				}
+				for _, i := range *a.Issues {
+					t.add(ptr.StringValue(a.WatchName), ptr.StringValue(i.Severity), ptr.StringValue(i.Type), ptr.StringValue(i.CVE), ptr.StringValue(i.Summary))
+				}
+			}
+		}
+		return t
+	})
+}
+
+func summaryArtifact(e *env, args []string) error {
+	flags := flag.NewFlagSet("summary artifact", flag.ContinueOnError)
+	var paths, checksums stringsFlag
+	flags.Var(&paths, "path", "artifact path, e.g. default/libs-release-local/foo.jar (repeatable)")
+	flags.Var(&checksums, "checksum", "artifact SHA-256 (repeatable)")
+	if err := parseFlags(flags, e, args); err != nil {
+		return err
+	}
+	if len(paths) == 0 && len(checksums) == 0 {
+		return errUsage("at least one -path or -checksum is required")
+	}
+
+	input := v1.GetArtifactSummaryInput{}
+	if len(paths) > 0 {
+		p := []string(paths)
+		input.Paths = &p
+	}
+	if len(checksums) > 0 {
+		c := []string(checksums)
+		input.Checksums = &c
+	}
+
+	summary, _, err := e.client.V1.Summary.GetArtifactSummary(e.ctx, &input)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(summary, func() *table {
+		t := &table{headers: []string{"ARTIFACT", "SEVERITY", "TYPE", "SUMMARY"}}
+		if summary.Artifacts != nil {
+			for _, a := range *summary.Artifacts {
+				var name string
+				if a.General != nil {
+					name = ptr.StringValue(a.General.Path)
+				}
+				if a.Issues == nil {
+					continue
+				}
+				for _, i := range *a.Issues {
+					t.add(name, ptr.StringValue(i.Severity), ptr.StringValue(i.IssueType), ptr.StringValue(i.Summary))
+				}
+			}
+		}
+		return t
+	})
+}
+
+func violationsList(e *env, args []string) error {
+	flags := flag.NewFlagSet("violations list", flag.ContinueOnError)
+	watch := flags.String("watch", "", "only violations of this watch")
+	violationType := flags.String("type", "", "only violations of this type: Security, License or Operational_Risk")
+	minSeverity := flags.String("min-severity", "", "only violations of at least this severity")
+	createdFrom := flags.String("created-from", "", "only violations created after this time, e.g. 2019-01-01T00:00:00Z")
+	limit := flags.Int("limit", 100, "maximum number of violations")
+	if err := parseFlags(flags, e, args); err != nil {
+		return err
+	}
+
+	filters := v1.GetViolationsFilters{}
+	for _, f := range []struct {
+		value  string
+		target **string
+	}{
+		{*watch, &filters.WatchName},
+		{*violationType, &filters.ViolationType},
+		{*minSeverity, &filters.MinimumSeverity},
+		{*createdFrom, &filters.CreatedFrom},
+	} {
+		if f.value != "" {
+			*f.target = v1.String(f.value)
+		}
+	}
+
+	input := v1.GetViolationsInput{
+		Filters:    &filters,
+		Pagination: &v1.GetViolationsPagination{Limit: limit},
+	}
+
+	output, _, err := e.client.V1.Violations.GetViolations(e.ctx, &input)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(output, func() *table {
+		t := &table{headers: []string{"CREATED", "WATCH", "SEVERITY", "TYPE", "ISSUE", "DESCRIPTION"}}
+		if output.Violations != nil {
+			for _, v := range *output.Violations {
+				t.add(ptr.StringValue(v.Created), ptr.StringValue(v.WatchName), ptr.StringValue(v.Severity), ptr.StringValue(v.Type), ptr.StringValue(v.IssueId), ptr.StringValue(v.Description))
+			}
+		}
+		return t
+	})
+}
+
+func reportsLicense(e *env, args []string) error {
+	report, _, err := e.client.V1.Reports.GetLicenseReport(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(report, func() *table {
+		t := &table{headers: []string{"LICENSE", "COMPONENTS"}}
+		if report.Distribution != nil {
+			for _, license := range sortedKeys(*report.Distribution) {
+				t.add(license, fmt.Sprintf("%d", (*report.Distribution)[license]))
+			}
+		}
+		return t
+	})
+}
+
+func systemPing(e *env, args []string) error {
+	output, _, err := e.client.V1.System.Ping(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(output, func() *table {
+		t := &table{headers: []string{"STATUS"}}
+		t.add(ptr.StringValue(output.Status))
+		return t
+	})
+}
+
+func systemVersion(e *env, args []string) error {
+	version, _, err := e.client.V1.System.Version(e.ctx)
+	if err != nil {
+		return err
+	}
+
+	return e.out.print(version, func() *table {
+		t := &table{headers: []string{"VERSION", "REVISION"}}
+		t.add(ptr.StringValue(version.Version), ptr.StringValue(version.Revision))
+		return t
+	})
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/atlassian/go-artifactory/v2/artifactory/transport"
+	"github.com/xero-oss/go-xray/internal/yamlutil"
+	"github.com/xero-oss/go-xray/xray"
+)
+
+// profile holds the connection settings of an Xray instance
+type profile struct {
+	Url      string `json:"url,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// profileFile is the content of the profile file, by default ~/.xray/config.yaml:
+//
+//	profiles:
+//	  default:
+//	    url: https://xray.example.com/
+//	    user: admin
+//	    password: secret
+type profileFile struct {
+	Profiles map[string]profile `json:"profiles,omitempty"`
+}
+
+// loadProfile reads the named profile from the profile file, then overrides it with the XRAY_URL, XRAY_USER,
+// XRAY_PASSWORD and XRAY_TOKEN environment variables. A missing profile file is not an error
+func loadProfile(name string, getenv func(string) string) (*profile, error) {
+	if name == "" {
+		name = getenv("XRAY_PROFILE")
+	}
+
+	path := getenv("XRAY_CONFIG")
+	if path == "" {
+		if home := getenv("HOME"); home != "" {
+			path = filepath.Join(home, ".xray", "config.yaml")
+		}
+	}
+
+	p := &profile{}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		if err == nil {
+			var file profileFile
+			if err := yamlutil.Unmarshal(data, &file); err != nil {
+				return nil, fmt.Errorf("reading %s: %s", path, err.Error())
+			}
+
+			lookup := name
+			if lookup == "" {
+				lookup = "default"
+			}
+
+			if found, ok := file.Profiles[lookup]; ok {
+				*p = found
+			} else if name != "" {
+				return nil, fmt.Errorf("profile %q not found in %s", name, path)
+			}
+		}
+	}
+
+	overrides := []struct {
+		env   string
+		value *string
+	}{
+		{"XRAY_URL", &p.Url},
+		{"XRAY_USER", &p.User},
+		{"XRAY_PASSWORD", &p.Password},
+		{"XRAY_TOKEN", &p.Token},
+	}
+	for _, o := range overrides {
+		if v := getenv(o.env); v != "" {
+			*o.value = v
+		}
+	}
+
+	if p.Url == "" {
+		return nil, fmt.Errorf("no Xray URL configured, set XRAY_URL or a profile")
+	}
+
+	return p, nil
+}
+
+// newClient creates a client authenticated with the token of the profile, or its user and password
+func (p *profile) newClient() (*xray.Xray, error) {
+	switch {
+	case p.Token != "":
+		tp := transport.AccessTokenAuth{AccessToken: p.Token}
+		return xray.NewClient(p.Url, tp.Client())
+	case p.User != "":
+		tp := transport.BasicAuth{Username: p.User, Password: p.Password}
+		return xray.NewClient(p.Url, tp.Client())
+	default:
+		return xray.NewClient(p.Url, nil)
+	}
+}
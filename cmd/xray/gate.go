@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xero-oss/go-xray/xray/gate"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// gateError reports a failed gate, the process exits with the code of its outcome
+type gateError struct {
+	verdict *gate.Verdict
+}
+
+func (e gateError) Error() string {
+	if e.verdict.Error != "" {
+		return fmt.Sprintf("gate failed: %s: %s", e.verdict.Outcome, e.verdict.Error)
+	}
+
+	return fmt.Sprintf("gate failed: %s", e.verdict.Outcome)
+}
+
+func (e gateError) ExitCode() int {
+	return e.verdict.ExitCode
+}
+
+func gateBuild(e *env, args []string) error {
+	flags := flag.NewFlagSet("gate build", flag.ContinueOnError)
+	name := flags.String("name", "", "build name")
+	number := flags.String("number", "", "build number")
+	artifactoryId := flags.String("artifactory-id", "", "id of the Artifactory instance holding the build")
+	minSeverity := flags.String("min-severity", "", "fail on issues of at least this severity")
+	maxAlerts := flags.Int("max-alerts", -1, "fail when there are more issues than this, negative disables the check")
+	ignoreFailBuild := flags.Bool("ignore-fail-build", false, "do not fail when Xray policies request the build to fail")
+	timeout := flags.Duration("timeout", gate.DefaultTimeout, "how long to wait for the scan results")
+	var allowed stringsFlag
+	flags.Var(&allowed, "allow", "CVE or issue ID to ignore (repeatable)")
+	if err := parseFlags(flags, e, args); err != nil {
+		return err
+	}
+	if *name == "" || *number == "" {
+		return errUsage("-name and -number are required")
+	}
+	if *minSeverity != "" && !severity.Valid(*minSeverity) {
+		return errUsage(fmt.Sprintf("invalid -min-severity %q, expected one of %s", *minSeverity, strings.Join(severity.All, ", ")))
+	}
+
+	thresholds := gate.Thresholds{
+		MinimumSeverity: *minSeverity,
+		AllowedCVEs:     allowed,
+		IgnoreFailBuild: *ignoreFailBuild,
+	}
+	if *maxAlerts >= 0 {
+		thresholds.MaxAlerts = maxAlerts
+	}
+
+	g := gate.New(e.client.V1.Scanning, thresholds)
+	g.Timeout = *timeout
+	if *timeout < time.Minute {
+		g.Interval = *timeout / 10
+	}
+
+	input := v1.ScanBuildInput{BuildName: name, BuildNumber: number}
+	if *artifactoryId != "" {
+		input.ArtifactoryId = artifactoryId
+	}
+
+	verdict := g.Check(e.ctx, &input)
+
+	var err error
+	if e.out.format == formatTable {
+		err = verdict.WriteSummary(e.stdout)
+	} else {
+		err = e.out.print(verdict, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	if verdict.Outcome != gate.OutcomePass {
+		return gateError{verdict: verdict}
+	}
+
+	return nil
+}
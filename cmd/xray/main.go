@@ -0,0 +1,135 @@
+// Command xray is a command-line client for the Xray API built on the go-xray library
+//
+// Usage:
+//
+//	xray [-profile name] [-o json|yaml|table] <command> <action> [flags] [args]
+//
+// The instance is configured with a profile from ~/.xray/config.yaml (or $XRAY_CONFIG), selected with -profile or
+// $XRAY_PROFILE, and the XRAY_URL, XRAY_USER, XRAY_PASSWORD and XRAY_TOKEN environment variables
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/xero-oss/go-xray/xray"
+)
+
+// env is what a command runs with
+type env struct {
+	ctx    context.Context
+	client *xray.Xray
+	out    *printer
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is an action of the CLI, e.g. "watches list"
+type command struct {
+	usage string
+	run   func(e *env, args []string) error
+}
+
+// errUsage is returned by commands called with invalid arguments
+type errUsage string
+
+func (e errUsage) Error() string { return string(e) }
+
+func (e errUsage) ExitCode() int { return 2 }
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr, os.Getenv); err != nil {
+		// -h and -help print the usage, which is all that was asked for
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+
+		fmt.Fprintln(os.Stderr, "error:", err)
+		if ec, ok := err.(exitCoder); ok {
+			os.Exit(ec.ExitCode())
+		}
+		os.Exit(1)
+	}
+}
+
+// exitCoder is implemented by errors requesting a specific exit code
+type exitCoder interface {
+	ExitCode() int
+}
+
+// run executes the command given by args, writing its output to stdout and the usage to stderr
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer, getenv func(string) string) error {
+	flags := flag.NewFlagSet("xray", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	profileName := flags.String("profile", "", "profile of the configuration file to use")
+	format := flags.String("o", formatTable, "output format: json, yaml or table")
+	flags.Usage = func() { usage(flags, stderr) }
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	rest := flags.Args()
+	if len(rest) < 2 {
+		usage(flags, stderr)
+		return errUsage("missing command")
+	}
+
+	name := rest[0] + " " + rest[1]
+	cmd, ok := commands[name]
+	if !ok {
+		usage(flags, stderr)
+		return errUsage(fmt.Sprintf("unknown command %q", name))
+	}
+
+	out, err := newPrinter(stdout, *format)
+	if err != nil {
+		return err
+	}
+
+	p, err := loadProfile(*profileName, getenv)
+	if err != nil {
+		return err
+	}
+
+	client, err := p.newClient()
+	if err != nil {
+		return err
+	}
+
+	return cmd.run(&env{ctx: ctx, client: client, out: out, stdout: stdout, stderr: stderr}, rest[2:])
+}
+
+func usage(flags *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "Usage: xray [flags] <command> <action> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-20s %s\n", name, commands[name].usage)
+	}
+}
+
+// stringsFlag is a flag that can be repeated
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(calls *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+
+		switch r.URL.Path {
+		case "/api/v1/system/version":
+			fmt.Fprint(w, `{"xray_version": "2.8.0", "xray_revision": "abc"}`)
+		case "/api/v1/policies":
+			if r.Method == "GET" {
+				fmt.Fprint(w, `[{"name": "high", "type": "security", "rules": [{"name": "r1", "criteria": {"min_severity": "High"}}]}]`)
+			}
+		case "/api/v2/watches":
+			if r.Method == "GET" {
+				fmt.Fprint(w, `[{"general_data": {"name": "prod", "active": true}, "assigned_policies": [{"name": "high"}]}]`)
+			}
+		}
+	}))
+}
+
+func testEnv(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestRun_systemVersionTable(t *testing.T) {
+	var calls []string
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"system", "version"}, &out, ioutil.Discard, testEnv(map[string]string{"XRAY_URL": server.URL}))
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !strings.Contains(out.String(), "VERSION") || !strings.Contains(out.String(), "2.8.0") {
+		t.Errorf("Expected a version table but got:\n%s", out.String())
+	}
+}
+
+func TestRun_watchesListYAML(t *testing.T) {
+	var calls []string
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"-o", "yaml", "watches", "list"}, &out, ioutil.Discard, testEnv(map[string]string{"XRAY_URL": server.URL}))
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !strings.Contains(out.String(), "general_data:") || !strings.Contains(out.String(), "name: prod") {
+		t.Errorf("Expected YAML output but got:\n%s", out.String())
+	}
+}
+
+func TestRun_policiesApplyFromProfile(t *testing.T) {
+	var calls []string
+	server := newTestServer(&calls)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "xray-cli")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	config := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(config, []byte("profiles:\n  staging:\n    url: "+server.URL+"\n"), 0600)
+
+	policies := filepath.Join(dir, "policies.yaml")
+	ioutil.WriteFile(policies, []byte(`
+- name: high
+  type: security
+  rules:
+    - name: r1
+      criteria:
+        min_severity: Critical
+- name: licenses
+  type: license
+`), 0600)
+
+	var out bytes.Buffer
+	err = run(context.Background(), []string{"-profile", "staging", "policies", "apply", "-f", policies}, &out, ioutil.Discard, testEnv(map[string]string{"XRAY_CONFIG": config}))
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !strings.Contains(out.String(), `create policy "licenses"`) || !strings.Contains(out.String(), `update policy "high"`) {
+		t.Errorf("Expected the plan to be printed but got:\n%s", out.String())
+	}
+
+	applied := strings.Join(calls, "\n")
+	if !strings.Contains(applied, "POST /api/v1/policies") || !strings.Contains(applied, "PUT /api/v1/policies/high") {
+		t.Errorf("Expected the policies to be created and updated but got:\n%s", applied)
+	}
+}
+
+func TestRun_usageErrors(t *testing.T) {
+	var out bytes.Buffer
+	env := testEnv(map[string]string{"XRAY_URL": "http://localhost"})
+
+	if err := run(context.Background(), []string{"watches"}, &out, ioutil.Discard, env); err == nil {
+		t.Errorf("Expected an error for a missing action but got nil")
+	}
+
+	if err := run(context.Background(), []string{"watches", "get"}, &out, ioutil.Discard, env); err == nil {
+		t.Errorf("Expected an error for a missing name but got nil")
+	}
+
+	if err := run(context.Background(), []string{"system", "ping"}, &out, ioutil.Discard, testEnv(nil)); err == nil {
+		t.Errorf("Expected an error for a missing URL but got nil")
+	}
+
+	err := run(context.Background(), []string{"gate", "build", "-name", "app", "-number", "1", "-min-severity", "Severe"}, &out, ioutil.Discard, env)
+	if _, ok := err.(errUsage); !ok {
+		t.Errorf("Expected a usage error for an invalid severity but got: %v", err)
+	}
+}
+
+func TestRun_help(t *testing.T) {
+	var out, stderr bytes.Buffer
+	env := testEnv(map[string]string{"XRAY_URL": "http://localhost"})
+
+	if err := run(context.Background(), []string{"-h"}, &out, &stderr, env); err != flag.ErrHelp {
+		t.Errorf("Expected flag.ErrHelp but got: %v", err)
+	}
+
+	if err := run(context.Background(), []string{"gate", "build", "-h"}, &out, &stderr, env); err != flag.ErrHelp {
+		t.Errorf("Expected flag.ErrHelp for a command but got: %v", err)
+	}
+
+	if out.Len() != 0 || !strings.Contains(stderr.String(), "Usage: xray") || !strings.Contains(stderr.String(), "-min-severity") {
+		t.Errorf("Expected the usage on stderr only but got stdout:\n%s\nstderr:\n%s", out.String(), stderr.String())
+	}
+}
+
+func TestRun_gateBuildExitCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"summary": {"fail_build": false}, "alerts": [{"watch_name": "prod", "issues": [{"cve": "CVE-1", "severity": "High", "type": "security"}]}]}`)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	env := testEnv(map[string]string{"XRAY_URL": server.URL})
+
+	err := run(context.Background(), []string{"gate", "build", "-name", "app", "-number", "1", "-min-severity", "Medium"}, &out, ioutil.Discard, env)
+	ec, ok := err.(exitCoder)
+	if !ok {
+		t.Fatalf("Expected an error with an exit code but got: %v", err)
+	}
+
+	if ec.ExitCode() != 4 {
+		t.Errorf("Expected exit code 4 but got: %d", ec.ExitCode())
+	}
+
+	if !strings.Contains(out.String(), "Xray gate FAILED") {
+		t.Errorf("Expected a summary but got:\n%s", out.String())
+	}
+
+	out.Reset()
+	err = run(context.Background(), []string{"gate", "build", "-name", "app", "-number", "1", "-allow", "CVE-1", "-min-severity", "Medium"}, &out, ioutil.Discard, env)
+	if err != nil {
+		t.Errorf("Expected the gate to pass but got: %s", err.Error())
+	}
+}
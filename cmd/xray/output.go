@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xero-oss/go-xray/internal/yamlutil"
+)
+
+// Output formats selected with the -o flag
+const (
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatTable = "table"
+)
+
+// table is the tabular rendering of a result
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(values ...string) {
+	t.rows = append(t.rows, values)
+}
+
+// printer writes results in the selected format
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case formatJSON, formatYAML, formatTable:
+		return &printer{w: w, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q, expected json, yaml or table", format)
+	}
+}
+
+// print writes v as JSON or YAML, or renders it with toTable for the table format
+func (p *printer) print(v interface{}, toTable func() *table) error {
+	switch p.format {
+	case formatYAML:
+		data, err := yamlutil.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(data)
+		return err
+	case formatTable:
+		if toTable != nil {
+			return p.table(toTable())
+		}
+	}
+
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *printer) table(t *table) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func boolStr(v *bool) string {
+	if v == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%t", *v)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -2,4 +2,7 @@ module github.com/xero-oss/go-xray
 
 go 1.12
 
-require github.com/atlassian/go-artifactory/v2 v2.3.0
+require (
+	github.com/atlassian/go-artifactory/v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,44 @@
+// Package golden compares the output of the report tests with the golden files of their testdata directories
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// Check compares the output with the file at path, or rewrites the file when the tests run with -update
+func Check(t *testing.T, path string, output []byte) {
+	golden := filepath.FromSlash(path)
+	if *update {
+		if err := ioutil.WriteFile(golden, output, 0644); err != nil {
+			t.Fatalf("Got the following error: %s", err.Error())
+		}
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !bytes.Equal(output, expected) {
+		t.Errorf("Output does not match %s, got:\n%s", golden, string(output))
+	}
+}
+
+// ReadJSON decodes the JSON file at path into v
+func ReadJSON(t *testing.T, path string, v interface{}) {
+	data, err := ioutil.ReadFile(filepath.FromSlash(path))
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+}
@@ -0,0 +1,141 @@
+// Package paging iterates over the paged results of the Xray APIs, only the current page is kept in memory
+package paging
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// DefaultPageSize is the number of records requested per page when no page size is set
+const DefaultPageSize = 100
+
+// ViolationsGetter is implemented by v1.ViolationsService
+type ViolationsGetter interface {
+	GetViolations(ctx context.Context, getViolationsInput *v1.GetViolationsInput) (*v1.GetViolationsOutput, *http.Response, error)
+}
+
+// Pager holds the state of an iteration over numbered pages
+type Pager struct {
+	page     int
+	index    int
+	size     int
+	fetched  int
+	total    *int
+	done     bool
+	err      error
+	pageSize int
+}
+
+// New creates a Pager requesting pageSize records per page, DefaultPageSize when pageSize is not positive
+func New(pageSize int) Pager {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return Pager{index: -1, pageSize: pageSize}
+}
+
+// Next moves to the next record, fetching the next page with fetch when the current one is exhausted. Pages are
+// numbered from 1, fetch returns the number of records of the page and the total reported by Xray, if any
+func (p *Pager) Next(ctx context.Context, fetch func(ctx context.Context, page int, pageSize int) (int, *int, error)) bool {
+	if p.err != nil {
+		return false
+	}
+
+	if p.index+1 < p.size {
+		p.index++
+		return true
+	}
+
+	// The last page was shorter than a full page or all the records reported by Xray were fetched
+	if p.done || (p.page > 0 && p.size < p.pageSize) || (p.total != nil && p.fetched >= *p.total) {
+		p.done = true
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	p.page++
+	size, total, err := fetch(ctx, p.page, p.pageSize)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.size, p.index, p.total = size, 0, total
+	p.fetched += size
+	if size == 0 {
+		p.done = true
+		return false
+	}
+
+	return true
+}
+
+// Index returns the position of the current record in the current page
+func (p *Pager) Index() int {
+	return p.index
+}
+
+// Err returns the error that stopped the iteration, if any
+func (p *Pager) Err() error {
+	return p.err
+}
+
+// ViolationIterator iterates over the violations matching filters in creation order, page by page
+type ViolationIterator struct {
+	violations ViolationsGetter
+	filters    *v1.GetViolationsFilters
+	pager      Pager
+	current    []v1.Violation
+}
+
+// NewViolationIterator creates an iterator over the violations matching filters, which can be nil, requesting pageSize
+// violations per page
+func NewViolationIterator(violations ViolationsGetter, filters *v1.GetViolationsFilters, pageSize int) *ViolationIterator {
+	return &ViolationIterator{violations: violations, filters: filters, pager: New(pageSize)}
+}
+
+// Next advances to the next violation and returns false at the end of the violations or on error, see Err
+func (it *ViolationIterator) Next(ctx context.Context) bool {
+	return it.pager.Next(ctx, func(ctx context.Context, page int, pageSize int) (int, *int, error) {
+		limit := pageSize
+		input := &v1.GetViolationsInput{
+			Filters: it.filters,
+			Pagination: &v1.GetViolationsPagination{
+				OrderBy:   v1.String("created"),
+				Direction: v1.String("asc"),
+				Limit:     &limit,
+				Offset:    v1.String(strconv.Itoa(page)),
+			},
+		}
+
+		output, _, err := it.violations.GetViolations(ctx, input)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		it.current = nil
+		if output.Violations != nil {
+			it.current = *output.Violations
+		}
+
+		return len(it.current), output.TotalViolations, nil
+	})
+}
+
+// Violation returns the current violation
+func (it *ViolationIterator) Violation() v1.Violation {
+	return it.current[it.pager.Index()]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ViolationIterator) Err() error {
+	return it.pager.Err()
+}
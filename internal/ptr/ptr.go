@@ -0,0 +1,20 @@
+// Package ptr reads the optional values of the API types, which are pointers so that unset fields are omitted
+package ptr
+
+// StringValue returns the string v points to, or "" when v is nil
+func StringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+
+	return *v
+}
+
+// IntValue returns the int v points to, or 0 when v is nil
+func IntValue(v *int) int {
+	if v == nil {
+		return 0
+	}
+
+	return *v
+}
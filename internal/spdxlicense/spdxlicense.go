@@ -0,0 +1,29 @@
+// Package spdxlicense tells the license names that are SPDX license ids from the ones that are not
+package spdxlicense
+
+import "strings"
+
+// canonical maps the lower case license ids to their spelling in the SPDX license list
+var canonical = make(map[string]string, len(ids))
+
+func init() {
+	for _, id := range ids {
+		canonical[strings.ToLower(id)] = id
+	}
+}
+
+// Id returns the SPDX license id of a license name, as spelled in the SPDX license list. License ids are matched case
+// insensitively, an optional + suffix is kept. It returns false for the names that are not on the list
+func Id(name string) (string, bool) {
+	suffix := ""
+	if strings.HasSuffix(name, "+") {
+		name, suffix = strings.TrimSuffix(name, "+"), "+"
+	}
+
+	id, ok := canonical[strings.ToLower(name)]
+	if !ok {
+		return "", false
+	}
+
+	return id + suffix, true
+}
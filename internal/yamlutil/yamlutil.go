@@ -0,0 +1,52 @@
+// Package yamlutil converts between YAML and the API types, which only carry JSON struct tags
+package yamlutil
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal encodes v as YAML using the field names of its JSON representation
+func Marshal(v interface{}) ([]byte, error) {
+	value, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(value)
+}
+
+// Unmarshal decodes YAML data into v using the field names of its JSON representation
+func Unmarshal(data []byte, v interface{}) error {
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	return FromValue(value, v)
+}
+
+// FromValue decodes a generic value, as produced by a YAML decoder, into v through its JSON representation
+func FromValue(value interface{}, v interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
@@ -0,0 +1,182 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+var sourceResponses = map[string]string{
+	"/api/v1/system/version":                 `{"xray_version": "2.8.0", "xray_revision": "abc"}`,
+	"/api/v1/users":                          `[{"name": "alice", "email": "alice@example.com"}]`,
+	"/api/v1/permissions":                    `[{"name": "readers", "uri": "/api/v1/permissions/readers"}]`,
+	"/api/v1/permissions/readers":            `{"name": "readers", "users": [{"name": "alice", "roles": ["view"]}]}`,
+	"/api/v1/binMgr":                         `[{"binMgrId": "default", "binMgrUrl": "https://artifactory", "user": "xray", "password": "s3cret"}]`,
+	"/api/v1/integration":                    `[{"vendor": "whitesource", "enabled": true}]`,
+	"/api/v1/policies":                       `[{"name": "high", "type": "security", "author": "admin", "created": "2019-01-01T00:00:00Z"}]`,
+	"/api/v2/watches":                        `[{"general_data": {"name": "prod"}, "assigned_policies": [{"name": "high", "type": "security"}]}]`,
+	"/api/v1/configuration/systemParameters": `{"jobInterval": 30}`,
+}
+
+func newServer(t *testing.T, responses map[string]string, calls *[]string) (*xray.Xray, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			*calls = append(*calls, r.Method+" "+r.URL.Path)
+			return
+		}
+
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			body = "[]"
+		}
+		fmt.Fprint(w, body)
+	}))
+
+	c, err := xray.NewClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	return c, server.Close
+}
+
+func TestExportRestore_roundTrip(t *testing.T) {
+	var sourceCalls []string
+	source, closeServer := newServer(t, sourceResponses, &sourceCalls)
+	defer closeServer()
+
+	bundle, err := Export(context.Background(), source)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	// The list APIs leave most secrets out, they get a placeholder anyway
+	if bundle.Users[0].Password == nil || *bundle.Users[0].Password != "{{secret:users/alice/password}}" {
+		t.Errorf("Expected the user password to be a placeholder but got: %v", bundle.Users[0].Password)
+	}
+
+	if bundle.Integrations[0].ApiKey == nil || *bundle.Integrations[0].ApiKey != "{{secret:integrations/whitesource/api_key}}" {
+		t.Errorf("Expected the integration API key to be a placeholder but got: %v", bundle.Integrations[0].ApiKey)
+	}
+
+	if *bundle.BinaryManagers[0].Password != "{{secret:binary_managers/default/password}}" {
+		t.Errorf("Expected the binary manager password to be a placeholder but got: %s", *bundle.BinaryManagers[0].Password)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, bundle); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if strings.Contains(buf.String(), "s3cret") {
+		t.Errorf("Expected secrets to be removed from the bundle but got:\n%s", buf.String())
+	}
+
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	var targetCalls []string
+	target, closeTarget := newServer(t, map[string]string{
+		"/api/v1/policies": `[{"name": "high"}]`,
+	}, &targetCalls)
+	defer closeTarget()
+
+	secrets := MapSecrets(map[string]string{
+		"users/alice/password":             "new-password",
+		"binary_managers/default/password": "new-secret",
+		"integrations/whitesource/api_key": "new-key",
+	})
+
+	result, err := Restore(context.Background(), target, read, RestoreOptions{Secrets: secrets, OnConflict: ConflictSkip})
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	expected := []string{
+		"POST /api/v1/binMgr",
+		"POST /api/v1/users",
+		"POST /api/v1/permissions",
+		"POST /api/v1/integration",
+		"POST /api/v2/watches",
+	}
+	if strings.Join(targetCalls, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected calls:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), strings.Join(targetCalls, "\n"))
+	}
+
+	sort.Strings(result.Skipped)
+	if strings.Join(result.Skipped, ",") != "policy/high,system_parameters/default" {
+		t.Errorf("Expected the existing policy and system parameters to be skipped but got: %v", result.Skipped)
+	}
+}
+
+func TestRestore_missingSecret(t *testing.T) {
+	var calls []string
+	target, closeServer := newServer(t, map[string]string{}, &calls)
+	defer closeServer()
+
+	bundle := &Bundle{Version: FormatVersion}
+	bundle.Integrations = append(bundle.Integrations, sourceIntegration())
+
+	if _, err := Restore(context.Background(), target, bundle, RestoreOptions{}); err == nil {
+		t.Errorf("Expected an error for an unresolved placeholder but got nil")
+	}
+
+	if len(calls) != 0 {
+		t.Errorf("Expected no calls but got: %v", calls)
+	}
+}
+
+func TestRestore_skippedWithoutSecret(t *testing.T) {
+	var calls []string
+	target, closeServer := newServer(t, map[string]string{"/api/v1/integration": `[{"vendor": "whitesource"}]`}, &calls)
+	defer closeServer()
+
+	bundle := &Bundle{Version: FormatVersion}
+	bundle.Integrations = append(bundle.Integrations, sourceIntegration())
+
+	result, err := Restore(context.Background(), target, bundle, RestoreOptions{OnConflict: ConflictSkip})
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if strings.Join(result.Skipped, ",") != "integration/whitesource" || len(calls) != 0 {
+		t.Errorf("Expected the existing integration to be skipped but got: %v and calls %v", result.Skipped, calls)
+	}
+}
+
+func TestRestore_conflictFail(t *testing.T) {
+	var calls []string
+	target, closeServer := newServer(t, map[string]string{"/api/v1/policies": `[{"name": "high"}]`}, &calls)
+	defer closeServer()
+
+	bundle := &Bundle{Version: FormatVersion}
+	bundle.Policies = append(bundle.Policies, sourcePolicy())
+
+	if _, err := Restore(context.Background(), target, bundle, RestoreOptions{OnConflict: ConflictFail}); err == nil {
+		t.Errorf("Expected an error for an existing policy but got nil")
+	}
+}
+
+func TestRead_unsupportedVersion(t *testing.T) {
+	if _, err := Read(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Errorf("Expected an error for a newer bundle version but got nil")
+	}
+}
+
+func sourceIntegration() v1.Integration {
+	return v1.Integration{Vendor: v1.String("whitesource"), ApiKey: v1.String(Placeholder("integrations/whitesource/api_key"))}
+}
+
+func sourcePolicy() v1.Policy {
+	return v1.Policy{Name: v1.String("high"), Type: v1.String("security")}
+}
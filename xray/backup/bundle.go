@@ -0,0 +1,96 @@
+// Package backup exports the configuration of an Xray instance into a versioned bundle and restores it
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/yamlutil"
+	"github.com/xero-oss/go-xray/xray/v1"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+// FormatVersion is the version of the bundle format written by Export
+const FormatVersion = 1
+
+const (
+	placeholderPrefix = "{{secret:"
+	placeholderSuffix = "}}"
+)
+
+// Bundle is a snapshot of the configuration of an Xray instance. Passwords and API keys are replaced by placeholders,
+// see Placeholder
+type Bundle struct {
+	Version          int                  `json:"version"`
+	Created          string               `json:"created,omitempty"`
+	XrayVersion      *v1.XrayVersion      `json:"xray_version,omitempty"`
+	Users            []v1.User            `json:"users,omitempty"`
+	Permissions      []v1.Permission      `json:"permissions,omitempty"`
+	BinaryManagers   []v1.BinaryManager   `json:"binary_managers,omitempty"`
+	Integrations     []v1.Integration     `json:"integrations,omitempty"`
+	Policies         []v1.Policy          `json:"policies,omitempty"`
+	Watches          []v2.Watch           `json:"watches,omitempty"`
+	SystemParameters *v1.SystemParameters `json:"system_parameters,omitempty"`
+}
+
+// Placeholder returns the value stored in a bundle instead of the secret identified by key,
+// e.g. {{secret:users/admin/password}}
+func Placeholder(key string) string {
+	return placeholderPrefix + key + placeholderSuffix
+}
+
+// PlaceholderKey returns the key of a placeholder and whether the value is a placeholder
+func PlaceholderKey(value string) (string, bool) {
+	if !strings.HasPrefix(value, placeholderPrefix) || !strings.HasSuffix(value, placeholderSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(value, placeholderPrefix), placeholderSuffix), true
+}
+
+// WriteJSON writes the bundle as indented JSON
+func WriteJSON(w io.Writer, bundle *Bundle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+// WriteYAML writes the bundle as YAML
+func WriteYAML(w io.Writer, bundle *Bundle) error {
+	data, err := yamlutil.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// Read reads a bundle written by WriteJSON or WriteYAML
+// It returns an error if the bundle was written by a newer format version
+func Read(r io.Reader) (*Bundle, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := new(Bundle)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		err = json.Unmarshal(data, bundle)
+	} else {
+		err = yamlutil.Unmarshal(data, bundle)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if bundle.Version < 1 || bundle.Version > FormatVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+
+	return bundle, nil
+}
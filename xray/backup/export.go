@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"context"
+	"time"
+
+	"github.com/xero-oss/go-xray/xray"
+)
+
+// Export snapshots users, permissions, binary managers, integrations, policies, watches and system parameters
+// Passwords and API keys are always replaced by placeholders, since the list APIs usually leave them out, so that
+// Restore asks for every secret
+func Export(ctx context.Context, client *xray.Xray) (*Bundle, error) {
+	bundle := &Bundle{
+		Version: FormatVersion,
+		Created: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	version, _, err := client.V1.System.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundle.XrayVersion = version
+
+	users, _, err := client.V1.Users.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range *users {
+		if u.Name != nil {
+			u.Password = xray.String(Placeholder("users/" + *u.Name + "/password"))
+		}
+		bundle.Users = append(bundle.Users, u)
+	}
+
+	permissions, _, err := client.V1.Permissions.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range *permissions {
+		if ref.Name == nil {
+			continue
+		}
+
+		permission, _, err := client.V1.Permissions.GetPermission(ctx, *ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Permissions = append(bundle.Permissions, *permission)
+	}
+
+	binMgrs, _, err := client.V1.BinaryManagers.ListBinaryManagers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range *binMgrs {
+		if b.Id != nil {
+			b.Password = xray.String(Placeholder("binary_managers/" + *b.Id + "/password"))
+		}
+		bundle.BinaryManagers = append(bundle.BinaryManagers, b)
+	}
+
+	integrations, _, err := client.V1.Integrations.ListIntegrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range *integrations {
+		if i.Vendor != nil {
+			i.ApiKey = xray.String(Placeholder("integrations/" + *i.Vendor + "/api_key"))
+		}
+		bundle.Integrations = append(bundle.Integrations, i)
+	}
+
+	policies, _, err := client.V1.Policies.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Policies = *policies
+
+	watches, _, err := client.V2.Watches.ListWatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Watches = *watches
+
+	parameters, _, err := client.V1.Configuration.GetSystemParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundle.SystemParameters = parameters
+
+	return bundle, nil
+}
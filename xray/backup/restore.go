@@ -0,0 +1,383 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/xero-oss/go-xray/xray"
+)
+
+// ConflictPolicy decides what Restore does with an object that already exists on the target instance
+type ConflictPolicy int
+
+const (
+	// ConflictSkip leaves the existing object untouched
+	ConflictSkip ConflictPolicy = iota
+
+	// ConflictOverwrite updates the existing object with the bundle content
+	ConflictOverwrite
+
+	// ConflictFail stops the restore with an error
+	ConflictFail
+)
+
+// SecretResolver returns the secret identified by a placeholder key, and false if it is unknown
+type SecretResolver func(key string) (string, bool)
+
+// MapSecrets resolves placeholders from a map of keys to secrets
+func MapSecrets(secrets map[string]string) SecretResolver {
+	return func(key string) (string, bool) {
+		v, ok := secrets[key]
+		return v, ok
+	}
+}
+
+// RestoreOptions configures Restore
+type RestoreOptions struct {
+	// OnConflict is applied to objects that already exist, defaults to ConflictSkip
+	OnConflict ConflictPolicy
+
+	// Secrets replaces the placeholders of the bundle, a placeholder it cannot resolve fails the restore
+	Secrets SecretResolver
+
+	// SkipSystemParameters leaves the system parameters of the target instance untouched
+	SkipSystemParameters bool
+}
+
+// RestoreResult lists the objects restored, as kind/name
+type RestoreResult struct {
+	Created []string
+	Updated []string
+	Skipped []string
+}
+
+type restorer struct {
+	ctx    context.Context
+	opts   RestoreOptions
+	result *RestoreResult
+}
+
+// Restore creates or updates the objects of the bundle on the instance. Objects are restored in dependency order:
+// binary managers, users, permissions, integrations, system parameters, policies and finally watches
+// It returns what was done until the first error
+func Restore(ctx context.Context, client *xray.Xray, bundle *Bundle, opts RestoreOptions) (*RestoreResult, error) {
+	r := &restorer{ctx: ctx, opts: opts, result: &RestoreResult{}}
+
+	steps := []func(*xray.Xray, *Bundle) error{
+		r.binaryManagers,
+		r.users,
+		r.permissions,
+		r.integrations,
+		r.systemParameters,
+		r.policies,
+		r.watches,
+	}
+
+	for _, step := range steps {
+		if err := step(client, bundle); err != nil {
+			return r.result, err
+		}
+	}
+
+	return r.result, nil
+}
+
+// restore creates the object if it does not exist, otherwise applies the conflict policy
+func (r *restorer) restore(kind string, name string, exists bool, create func() (*http.Response, error), update func() (*http.Response, error)) error {
+	id := kind + "/" + name
+
+	if !exists {
+		if _, err := create(); err != nil {
+			return fmt.Errorf("creating %s: %s", id, err.Error())
+		}
+		r.result.Created = append(r.result.Created, id)
+		return nil
+	}
+
+	switch r.opts.OnConflict {
+	case ConflictOverwrite:
+		if _, err := update(); err != nil {
+			return fmt.Errorf("updating %s: %s", id, err.Error())
+		}
+		r.result.Updated = append(r.result.Updated, id)
+	case ConflictFail:
+		return fmt.Errorf("%s already exists", id)
+	default:
+		r.result.Skipped = append(r.result.Skipped, id)
+	}
+
+	return nil
+}
+
+// secret replaces a placeholder by its secret in place, other values are left as is. It is called when the object is
+// created or updated, so that objects skipped by the conflict policy do not need their secrets
+func (r *restorer) secret(value **string) error {
+	if *value == nil {
+		return nil
+	}
+
+	key, ok := PlaceholderKey(**value)
+	if !ok {
+		return nil
+	}
+
+	if r.opts.Secrets != nil {
+		if secret, ok := r.opts.Secrets(key); ok {
+			*value = xray.String(secret)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no secret provided for %s", key)
+}
+
+func (r *restorer) binaryManagers(client *xray.Xray, bundle *Bundle) error {
+	if len(bundle.BinaryManagers) == 0 {
+		return nil
+	}
+
+	existing, _, err := client.V1.BinaryManagers.ListBinaryManagers(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := map[string]bool{}
+	for _, b := range *existing {
+		if b.Id != nil {
+			ids[*b.Id] = true
+		}
+	}
+
+	for _, b := range bundle.BinaryManagers {
+		b := b
+		if b.Id == nil {
+			return fmt.Errorf("binary manager without id")
+		}
+
+		err := r.restore("binary_manager", *b.Id, ids[*b.Id],
+			func() (*http.Response, error) {
+				if err := r.secret(&b.Password); err != nil {
+					return nil, err
+				}
+				return client.V1.BinaryManagers.CreateBinaryManager(r.ctx, &b)
+			},
+			func() (*http.Response, error) {
+				if err := r.secret(&b.Password); err != nil {
+					return nil, err
+				}
+				return client.V1.BinaryManagers.UpdateBinaryManager(r.ctx, *b.Id, &b)
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) users(client *xray.Xray, bundle *Bundle) error {
+	if len(bundle.Users) == 0 {
+		return nil
+	}
+
+	existing, _, err := client.V1.Users.ListUsers(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for _, u := range *existing {
+		if u.Name != nil {
+			names[*u.Name] = true
+		}
+	}
+
+	for _, u := range bundle.Users {
+		u := u
+		if u.Name == nil {
+			return fmt.Errorf("user without name")
+		}
+
+		err := r.restore("user", *u.Name, names[*u.Name],
+			func() (*http.Response, error) {
+				if err := r.secret(&u.Password); err != nil {
+					return nil, err
+				}
+				return client.V1.Users.CreateUser(r.ctx, &u)
+			},
+			func() (*http.Response, error) {
+				if err := r.secret(&u.Password); err != nil {
+					return nil, err
+				}
+				return client.V1.Users.UpdateUser(r.ctx, *u.Name, &u)
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) permissions(client *xray.Xray, bundle *Bundle) error {
+	if len(bundle.Permissions) == 0 {
+		return nil
+	}
+
+	existing, _, err := client.V1.Permissions.ListPermissions(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for _, p := range *existing {
+		if p.Name != nil {
+			names[*p.Name] = true
+		}
+	}
+
+	for _, p := range bundle.Permissions {
+		p := p
+		if p.Name == nil {
+			return fmt.Errorf("permission without name")
+		}
+
+		err := r.restore("permission", *p.Name, names[*p.Name],
+			func() (*http.Response, error) { return client.V1.Permissions.CreatePermission(r.ctx, &p) },
+			func() (*http.Response, error) { return client.V1.Permissions.UpdatePermission(r.ctx, *p.Name, &p) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) integrations(client *xray.Xray, bundle *Bundle) error {
+	if len(bundle.Integrations) == 0 {
+		return nil
+	}
+
+	existing, _, err := client.V1.Integrations.ListIntegrations(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	vendors := map[string]bool{}
+	for _, i := range *existing {
+		if i.Vendor != nil {
+			vendors[*i.Vendor] = true
+		}
+	}
+
+	for _, i := range bundle.Integrations {
+		i := i
+		if i.Vendor == nil {
+			return fmt.Errorf("integration without vendor")
+		}
+
+		err := r.restore("integration", *i.Vendor, vendors[*i.Vendor],
+			func() (*http.Response, error) {
+				if err := r.secret(&i.ApiKey); err != nil {
+					return nil, err
+				}
+				return client.V1.Integrations.CreateIntegration(r.ctx, &i)
+			},
+			func() (*http.Response, error) {
+				if err := r.secret(&i.ApiKey); err != nil {
+					return nil, err
+				}
+				return client.V1.Integrations.UpdateIntegration(r.ctx, *i.Vendor, &i)
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) systemParameters(client *xray.Xray, bundle *Bundle) error {
+	if bundle.SystemParameters == nil || r.opts.SkipSystemParameters {
+		return nil
+	}
+
+	// The system parameters always exist, so they follow the conflict policy
+	return r.restore("system_parameters", "default", true, nil,
+		func() (*http.Response, error) {
+			return client.V1.Configuration.UpdateSystemParameters(r.ctx, bundle.SystemParameters)
+		})
+}
+
+func (r *restorer) policies(client *xray.Xray, bundle *Bundle) error {
+	if len(bundle.Policies) == 0 {
+		return nil
+	}
+
+	existing, _, err := client.V1.Policies.ListPolicies(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for _, p := range *existing {
+		if p.Name != nil {
+			names[*p.Name] = true
+		}
+	}
+
+	for _, p := range bundle.Policies {
+		p := p
+		if p.Name == nil {
+			return fmt.Errorf("policy without name")
+		}
+
+		// Set by Xray
+		p.Author, p.Created, p.Modified = nil, nil, nil
+
+		err := r.restore("policy", *p.Name, names[*p.Name],
+			func() (*http.Response, error) { return client.V1.Policies.CreatePolicy(r.ctx, &p) },
+			func() (*http.Response, error) { return client.V1.Policies.UpdatePolicy(r.ctx, *p.Name, &p) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) watches(client *xray.Xray, bundle *Bundle) error {
+	if len(bundle.Watches) == 0 {
+		return nil
+	}
+
+	existing, _, err := client.V2.Watches.ListWatches(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for _, w := range *existing {
+		if w.GeneralData != nil && w.GeneralData.Name != nil {
+			names[*w.GeneralData.Name] = true
+		}
+	}
+
+	for _, w := range bundle.Watches {
+		w := w
+		if w.GeneralData == nil || w.GeneralData.Name == nil {
+			return fmt.Errorf("watch without name")
+		}
+
+		name := *w.GeneralData.Name
+		err := r.restore("watch", name, names[name],
+			func() (*http.Response, error) { return client.V2.Watches.CreateWatch(r.ctx, &w) },
+			func() (*http.Response, error) { return client.V2.Watches.UpdateWatch(r.ctx, name, &w) })
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+func TestWatchBuilder(t *testing.T) {
+	w, err := NewWatch("releases").
+		Description("Release repositories").
+		Repo("libs-release", "default").
+		WithRepoType("local").
+		WithPackageTypeFilter("npm").
+		WithPropertyFilter("release", "true").
+		AllBuilds("default", []string{"app-*"}, nil).
+		AssignSecurityPolicy("high-severity").
+		AssignLicensePolicy("licenses").
+		Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if *w.GeneralData.Name != "releases" || !*w.GeneralData.Active || *w.GeneralData.Description != "Release repositories" {
+		t.Errorf("Unexpected general data: %+v", w.GeneralData)
+	}
+
+	resources := *w.ProjectResources.Resources
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 resources but got: %d", len(resources))
+	}
+
+	repo := resources[0]
+	if *repo.Type != v2.WatchResourceTypeRepository || *repo.Name != "libs-release" || *repo.BinaryManagerId != "default" || *repo.RepoType != "local" {
+		t.Errorf("Unexpected repository: %+v", repo)
+	}
+
+	filters := *repo.Filters
+	if len(filters) != 2 || *filters[0].Type != v2.WatchFilterTypePackageType || *filters[0].Value.Value != "npm" {
+		t.Errorf("Unexpected filters: %+v", filters)
+	}
+	if !filters[1].Value.IsPropertyFilter || *filters[1].Value.Key != "release" {
+		t.Errorf("Unexpected property filter: %+v", filters[1].Value)
+	}
+
+	builds := resources[1]
+	if *builds.Type != v2.WatchResourceTypeAllBuilds || builds.Name != nil {
+		t.Errorf("Unexpected builds resource: %+v", builds)
+	}
+	if patterns := (*builds.Filters)[0].Value.AntPatterns.IncludePatterns; strings.Join(*patterns, ",") != "app-*" {
+		t.Errorf("Unexpected include patterns: %v", *patterns)
+	}
+
+	policies := *w.AssignedPolicies
+	if len(policies) != 2 || *policies[0].Type != v2.PolicyTypeSecurity || *policies[1].Name != "licenses" {
+		t.Errorf("Unexpected assigned policies: %+v", policies)
+	}
+}
+
+func TestWatchBuilder_reuse(t *testing.T) {
+	b := NewWatch("releases").Repo("libs-release", "default").WithPackageTypeFilter("npm")
+
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	b.WithMimeTypeFilter("application/json")
+	if _, err := b.Build(); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if n := len(*(*first.ProjectResources.Resources)[0].Filters); n != 1 {
+		t.Errorf("Expected the first watch to keep a single filter but got: %d", n)
+	}
+}
+
+func TestWatchBuilder_copiesFilterValues(t *testing.T) {
+	b := NewWatch("releases").Repo("libs-release", "default").WithPathAntPatternsFilter([]string{"app/**"}, nil)
+
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	(*(*(*first.ProjectResources.Resources)[0].Filters)[0].Value.AntPatterns.IncludePatterns)[0] = "changed/**"
+
+	second, err := b.Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if patterns := *(*(*second.ProjectResources.Resources)[0].Filters)[0].Value.AntPatterns.IncludePatterns; patterns[0] != "app/**" {
+		t.Errorf("Expected the builder to keep its patterns but got: %v", patterns)
+	}
+}
+
+func TestWatchBuilder_copiesResources(t *testing.T) {
+	b := NewWatch("releases").Repo("libs-release", "default").WithPropertyFilter("team", "core").AssignSecurityPolicy("high-severity")
+
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	resource := (*first.ProjectResources.Resources)[0]
+	*resource.Name = "changed"
+	*resource.BinaryManagerId = "changed"
+	*(*resource.Filters)[0].Value.Value = "changed"
+	*(*first.AssignedPolicies)[0].Name = "changed"
+
+	second, err := b.Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	resource = (*second.ProjectResources.Resources)[0]
+	if *resource.Name != "libs-release" || *resource.BinaryManagerId != "default" {
+		t.Errorf("Expected the builder to keep its resource but got: %s %s", *resource.Name, *resource.BinaryManagerId)
+	}
+	if v := *(*resource.Filters)[0].Value.Value; v != "core" {
+		t.Errorf("Expected the builder to keep its filter value but got: %s", v)
+	}
+	if name := *(*second.AssignedPolicies)[0].Name; name != "high-severity" {
+		t.Errorf("Expected the builder to keep its policies but got: %s", name)
+	}
+}
+
+func TestWatchBuilder_errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		builder  *WatchBuilder
+		expected string
+	}{
+		{
+			name:     "no name",
+			builder:  NewWatch("").AllRepos("default"),
+			expected: "watch name is required",
+		},
+		{
+			name:     "no resource",
+			builder:  NewWatch("w"),
+			expected: "invalid watch \"w\": at least one resource is required",
+		},
+		{
+			name:     "filter before any resource",
+			builder:  NewWatch("w").WithPackageTypeFilter("npm").AllRepos("default"),
+			expected: "invalid watch \"w\": package-type filter set before any resource",
+		},
+		{
+			name:     "illegal filter",
+			builder:  NewWatch("w").BuildResource("app", "default").WithRegexFilter(".*"),
+			expected: "invalid watch \"w\": invalid watch resource 0: filter type \"regex\" is not allowed for resource type \"build\"",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.builder.Build()
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+
+			if err.Error() != c.expected {
+				t.Errorf("Expected the error %q but got: %q", c.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestPolicyBuilder(t *testing.T) {
+	p, err := NewSecurityPolicy("high-severity").
+		Description("Fails the builds with high severity issues").
+		Rule("critical").MinSeverity("Critical").FailBuild().BlockDownload(false).
+		Rule("cvss").CVSSRange(7, 10).Mails("security@example.com").Mails("platform@example.com").
+		Rule("low").Priority(10).MinSeverity("Low").Webhooks("https://hooks.example.com/xray").CustomSeverity("Information").
+		Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if *p.Name != "high-severity" || *p.Type != "security" {
+		t.Errorf("Unexpected policy: %s %s", *p.Name, *p.Type)
+	}
+
+	rules := *p.Rules
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules but got: %d", len(rules))
+	}
+
+	critical := rules[0]
+	if *critical.Priority != 1 || *critical.Criteria.MinimumSeverity != "Critical" || !*critical.Actions.FailBuild {
+		t.Errorf("Unexpected critical rule: %+v", critical)
+	}
+	if !*critical.Actions.BlockDownload.Active || *critical.Actions.BlockDownload.Unscanned {
+		t.Errorf("Unexpected block download: %+v", critical.Actions.BlockDownload)
+	}
+
+	cvss := rules[1]
+	if *cvss.Priority != 2 || *cvss.Criteria.CVSSRange.From != 7 || *cvss.Criteria.CVSSRange.To != 10 {
+		t.Errorf("Unexpected CVSS rule: %+v", cvss.Criteria.CVSSRange)
+	}
+	if mails := strings.Join(*cvss.Actions.Mails, ","); mails != "security@example.com,platform@example.com" {
+		t.Errorf("Unexpected mails: %s", mails)
+	}
+
+	low := rules[2]
+	if *low.Priority != 10 || *low.Actions.CustomSeverity != "Information" || (*low.Actions.Webhooks)[0] != "https://hooks.example.com/xray" {
+		t.Errorf("Unexpected low rule: %+v", low.Actions)
+	}
+}
+
+func TestPolicyBuilder_license(t *testing.T) {
+	p, err := NewLicensePolicy("licenses").
+		Rule("gpl").BannedLicenses("GPL-2.0", "GPL-3.0").FailBuild().
+		Rule("unknown").AllowedLicenses("MIT", "Apache-2.0").AllowUnknown(false).Mails("legal@example.com").
+		Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	rules := *p.Rules
+	if banned := strings.Join(*rules[0].Criteria.BannedLicenses, ","); banned != "GPL-2.0,GPL-3.0" {
+		t.Errorf("Unexpected banned licenses: %s", banned)
+	}
+	if rules[1].Criteria.AllowUnkown == nil || *rules[1].Criteria.AllowUnkown {
+		t.Errorf("Expected unknown licenses not to be allowed")
+	}
+}
+
+func TestPolicyBuilder_reuse(t *testing.T) {
+	licenses := []string{"GPL-2.0", "GPL-3.0"}
+	b := NewLicensePolicy("licenses").Rule("gpl").BannedLicenses(licenses...).Mails("legal@example.com").BlockDownload(false)
+
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	licenses[0] = "MIT"
+	rule := (*first.Rules)[0]
+	(*rule.Criteria.BannedLicenses)[1] = "AGPL-3.0"
+	(*rule.Actions.Mails)[0] = "changed@example.com"
+	rule.Actions.BlockDownload.Unscanned = xray.Bool(true)
+
+	second, err := b.Build()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	rule = (*second.Rules)[0]
+	if banned := strings.Join(*rule.Criteria.BannedLicenses, ","); banned != "GPL-2.0,GPL-3.0" {
+		t.Errorf("Expected the builder to keep its licenses but got: %s", banned)
+	}
+	if mails := strings.Join(*rule.Actions.Mails, ","); mails != "legal@example.com" {
+		t.Errorf("Expected the builder to keep its mails but got: %s", mails)
+	}
+	if *rule.Actions.BlockDownload.Unscanned {
+		t.Errorf("Expected the builder to keep its block download settings")
+	}
+}
+
+func TestPolicyBuilder_errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		builder  *PolicyBuilder
+		expected string
+	}{
+		{
+			name:     "criteria before any rule",
+			builder:  NewSecurityPolicy("p").MinSeverity("High").Rule("r"),
+			expected: "invalid policy \"p\": min_severity set before any rule",
+		},
+		{
+			name:     "no rule",
+			builder:  NewSecurityPolicy("p"),
+			expected: "invalid policy \"p\": rules: ",
+		},
+		{
+			name:     "license criteria in a security policy",
+			builder:  NewSecurityPolicy("p").Rule("r").BannedLicenses("GPL-3.0").FailBuild(),
+			expected: "invalid policy \"p\": rules[0].criteria: ",
+		},
+		{
+			name:     "unknown type",
+			builder:  NewPolicy("p", "operational_risk").Rule("r").MinSeverity("High").FailBuild(),
+			expected: "invalid policy \"p\": type: ",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.builder.Build()
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+
+			if !strings.HasPrefix(err.Error(), c.expected) {
+				t.Errorf("Expected the error %q but got: %q", c.expected, err.Error())
+			}
+		})
+	}
+}
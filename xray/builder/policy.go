@@ -0,0 +1,254 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/policylint"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// PolicyBuilder builds a v1.Policy. Criteria and actions apply to the last rule added
+type PolicyBuilder struct {
+	name        string
+	policyType  string
+	description string
+	rules       []v1.PolicyRule
+	err         error
+}
+
+// NewPolicy starts a policy of the given type
+func NewPolicy(name string, policyType string) *PolicyBuilder {
+	return &PolicyBuilder{name: name, policyType: policyType}
+}
+
+// NewSecurityPolicy starts a security policy
+func NewSecurityPolicy(name string) *PolicyBuilder {
+	return NewPolicy(name, v1.PolicyTypeSecurity)
+}
+
+// NewLicensePolicy starts a license policy
+func NewLicensePolicy(name string) *PolicyBuilder {
+	return NewPolicy(name, v1.PolicyTypeLicense)
+}
+
+// Description sets the description of the policy
+func (b *PolicyBuilder) Description(description string) *PolicyBuilder {
+	b.description = description
+	return b
+}
+
+// Rule adds a rule, its priority is its position in the policy unless Priority is set
+func (b *PolicyBuilder) Rule(name string) *PolicyBuilder {
+	b.rules = append(b.rules, v1.PolicyRule{
+		Name:     v1.String(name),
+		Priority: xray.Int(len(b.rules) + 1),
+		Criteria: &v1.PolicyRuleCriteria{},
+	})
+	return b
+}
+
+// Priority sets the priority of the last rule, the lowest number is applied first
+func (b *PolicyBuilder) Priority(priority int) *PolicyBuilder {
+	if r := b.last("priority"); r != nil {
+		r.Priority = xray.Int(priority)
+	}
+
+	return b
+}
+
+// MinSeverity matches the security issues at least as severe as the severity
+func (b *PolicyBuilder) MinSeverity(severity string) *PolicyBuilder {
+	if r := b.last("min_severity"); r != nil {
+		r.Criteria.MinimumSeverity = v1.String(severity)
+	}
+
+	return b
+}
+
+// CVSSRange matches the security issues with a CVSS score in the range
+func (b *PolicyBuilder) CVSSRange(from int, to int) *PolicyBuilder {
+	if r := b.last("cvss_range"); r != nil {
+		r.Criteria.CVSSRange = &v1.PolicyCVSSRange{From: xray.Int(from), To: xray.Int(to)}
+	}
+
+	return b
+}
+
+// BannedLicenses matches the components with one of the licenses
+func (b *PolicyBuilder) BannedLicenses(licenses ...string) *PolicyBuilder {
+	if r := b.last("banned_licenses"); r != nil {
+		r.Criteria.BannedLicenses = copyStrings(&licenses)
+	}
+
+	return b
+}
+
+// AllowedLicenses matches the components with none of the licenses
+func (b *PolicyBuilder) AllowedLicenses(licenses ...string) *PolicyBuilder {
+	if r := b.last("allowed_licenses"); r != nil {
+		r.Criteria.AllowedLicenses = copyStrings(&licenses)
+	}
+
+	return b
+}
+
+// AllowUnknown sets whether the components with an unknown license are allowed
+func (b *PolicyBuilder) AllowUnknown(allow bool) *PolicyBuilder {
+	if r := b.last("allow_unknown"); r != nil {
+		r.Criteria.AllowUnkown = xray.Bool(allow)
+	}
+
+	return b
+}
+
+// FailBuild fails the builds with violations of the last rule
+func (b *PolicyBuilder) FailBuild() *PolicyBuilder {
+	if a := b.actions("fail_build"); a != nil {
+		a.FailBuild = xray.Bool(true)
+	}
+
+	return b
+}
+
+// BlockDownload blocks the download of the artifacts with violations of the last rule, and of the unscanned artifacts
+// when unscanned is true
+func (b *PolicyBuilder) BlockDownload(unscanned bool) *PolicyBuilder {
+	if a := b.actions("block_download"); a != nil {
+		a.BlockDownload = &v1.BlockDownloadSettings{Active: xray.Bool(true), Unscanned: xray.Bool(unscanned)}
+	}
+
+	return b
+}
+
+// Mails adds recipients of the violations of the last rule
+func (b *PolicyBuilder) Mails(mails ...string) *PolicyBuilder {
+	if a := b.actions("mails"); a != nil {
+		a.Mails = appendStrings(a.Mails, mails)
+	}
+
+	return b
+}
+
+// Webhooks adds webhooks called with the violations of the last rule
+func (b *PolicyBuilder) Webhooks(webhooks ...string) *PolicyBuilder {
+	if a := b.actions("webhooks"); a != nil {
+		a.Webhooks = appendStrings(a.Webhooks, webhooks)
+	}
+
+	return b
+}
+
+// CustomSeverity overrides the severity of the violations of the last rule
+func (b *PolicyBuilder) CustomSeverity(severity string) *PolicyBuilder {
+	if a := b.actions("custom_severity"); a != nil {
+		a.CustomSeverity = v1.String(severity)
+	}
+
+	return b
+}
+
+// Build returns the policy, or the first misuse of the builder or the errors found by policylint. Warnings are ignored
+func (b *PolicyBuilder) Build() (*v1.Policy, error) {
+	if b.err != nil {
+		return nil, fmt.Errorf("invalid policy %q: %s", b.name, b.err.Error())
+	}
+
+	p := &v1.Policy{Name: v1.String(b.name), Type: v1.String(b.policyType)}
+	if b.description != "" {
+		p.Description = v1.String(b.description)
+	}
+
+	rules := make([]v1.PolicyRule, len(b.rules))
+	for i, r := range b.rules {
+		rules[i] = copyRule(r)
+	}
+	p.Rules = &rules
+
+	if err := policylint.Err(policylint.Policy(p)); err != nil {
+		return nil, fmt.Errorf("invalid policy %q: %s", b.name, err.Error())
+	}
+
+	return p, nil
+}
+
+// last returns the last rule, or records that what was set before any rule
+func (b *PolicyBuilder) last(what string) *v1.PolicyRule {
+	if len(b.rules) == 0 {
+		if b.err == nil {
+			b.err = fmt.Errorf("%s set before any rule", what)
+		}
+		return nil
+	}
+
+	return &b.rules[len(b.rules)-1]
+}
+
+// actions returns the actions of the last rule, creating them if needed
+func (b *PolicyBuilder) actions(what string) *v1.PolicyRuleActions {
+	r := b.last(what)
+	if r == nil {
+		return nil
+	}
+
+	if r.Actions == nil {
+		r.Actions = &v1.PolicyRuleActions{}
+	}
+
+	return r.Actions
+}
+
+// copyRule copies the criteria and actions of a rule, down to their lists and settings, so that the builder can be
+// reused after Build and the built policy changed without affecting it
+func copyRule(r v1.PolicyRule) v1.PolicyRule {
+	if r.Criteria != nil {
+		c := *r.Criteria
+		if c.CVSSRange != nil {
+			cvss := *c.CVSSRange
+			c.CVSSRange = &cvss
+		}
+		c.BannedLicenses = copyStrings(c.BannedLicenses)
+		c.AllowedLicenses = copyStrings(c.AllowedLicenses)
+		r.Criteria = &c
+	}
+
+	if r.Actions != nil {
+		a := *r.Actions
+		if a.BlockDownload != nil {
+			block := *a.BlockDownload
+			a.BlockDownload = &block
+		}
+		a.Mails = copyStrings(a.Mails)
+		a.Webhooks = copyStrings(a.Webhooks)
+		r.Actions = &a
+	}
+
+	return r
+}
+
+func copyString(s *string) *string {
+	if s == nil {
+		return nil
+	}
+
+	v := *s
+	return &v
+}
+
+func copyStrings(list *[]string) *[]string {
+	if list == nil {
+		return nil
+	}
+
+	return appendStrings(nil, *list)
+}
+
+func appendStrings(list *[]string, values []string) *[]string {
+	all := []string{}
+	if list != nil {
+		all = append(all, *list...)
+	}
+	all = append(all, values...)
+
+	return &all
+}
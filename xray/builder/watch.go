@@ -0,0 +1,249 @@
+// Package builder constructs watches and policies with fluent builders, without the pointer handling of the API types
+//
+//	watch, err := builder.NewWatch("releases").
+//		Repo("libs-release", "default").
+//		WithPackageTypeFilter("npm").
+//		AssignSecurityPolicy("high-severity").
+//		Build()
+//
+// The builders record the first misuse and return it from Build, along with the validation errors of the result
+package builder
+
+import (
+	"fmt"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+// WatchBuilder builds a v2.Watch. Filters apply to the last resource added
+type WatchBuilder struct {
+	name        string
+	description string
+	active      bool
+	resources   []v2.WatchProjectResource
+	policies    []v2.WatchAssignedPolicy
+	err         error
+}
+
+// NewWatch starts an active watch
+func NewWatch(name string) *WatchBuilder {
+	return &WatchBuilder{name: name, active: true}
+}
+
+// Description sets the description of the watch
+func (b *WatchBuilder) Description(description string) *WatchBuilder {
+	b.description = description
+	return b
+}
+
+// Active sets whether the watch is active
+func (b *WatchBuilder) Active(active bool) *WatchBuilder {
+	b.active = active
+	return b
+}
+
+// Repo adds a repository managed by the binary manager
+func (b *WatchBuilder) Repo(name string, binMgrId string) *WatchBuilder {
+	return b.resource(v2.NewRepositoryResource(binMgrId, name))
+}
+
+// AllRepos adds every repository managed by the binary manager
+func (b *WatchBuilder) AllRepos(binMgrId string) *WatchBuilder {
+	return b.resource(v2.NewAllReposResource(binMgrId))
+}
+
+// BuildResource adds a build
+func (b *WatchBuilder) BuildResource(name string, binMgrId string) *WatchBuilder {
+	return b.resource(v2.NewBuildResource(binMgrId, name))
+}
+
+// AllBuilds adds the builds matching the include patterns and none of the exclude patterns
+func (b *WatchBuilder) AllBuilds(binMgrId string, includePatterns []string, excludePatterns []string) *WatchBuilder {
+	return b.resource(v2.NewAllBuildsResource(binMgrId, includePatterns, excludePatterns))
+}
+
+// Project adds a project
+func (b *WatchBuilder) Project(name string, binMgrId string) *WatchBuilder {
+	return b.resource(v2.NewProjectResource(binMgrId, name))
+}
+
+// AllProjects adds the projects matching the include patterns and none of the exclude patterns
+func (b *WatchBuilder) AllProjects(binMgrId string, includePatterns []string, excludePatterns []string) *WatchBuilder {
+	return b.resource(v2.NewAllProjectsResource(binMgrId, includePatterns, excludePatterns))
+}
+
+// ReleaseBundle adds a release bundle
+func (b *WatchBuilder) ReleaseBundle(name string, binMgrId string) *WatchBuilder {
+	return b.resource(v2.NewReleaseBundleResource(binMgrId, name))
+}
+
+// AllReleaseBundles adds the release bundles matching the include patterns and none of the exclude patterns
+func (b *WatchBuilder) AllReleaseBundles(binMgrId string, includePatterns []string, excludePatterns []string) *WatchBuilder {
+	return b.resource(v2.NewAllReleaseBundlesResource(binMgrId, includePatterns, excludePatterns))
+}
+
+// WithRepoType sets the repository type of the last resource, such as local or remote
+func (b *WatchBuilder) WithRepoType(repoType string) *WatchBuilder {
+	if r := b.last("repository type"); r != nil {
+		r.RepoType = v2.String(repoType)
+	}
+
+	return b
+}
+
+// WithRegexFilter filters the last resource by artifact name
+func (b *WatchBuilder) WithRegexFilter(regex string) *WatchBuilder {
+	return b.filter(v2.NewRegexFilter(regex))
+}
+
+// WithPackageTypeFilter filters the last resource by package type
+func (b *WatchBuilder) WithPackageTypeFilter(packageType string) *WatchBuilder {
+	return b.filter(v2.NewPackageTypeFilter(packageType))
+}
+
+// WithMimeTypeFilter filters the last resource by mime type
+func (b *WatchBuilder) WithMimeTypeFilter(mimeType string) *WatchBuilder {
+	return b.filter(v2.NewMimeTypeFilter(mimeType))
+}
+
+// WithPathRegexFilter filters the last resource by artifact path
+func (b *WatchBuilder) WithPathRegexFilter(regex string) *WatchBuilder {
+	return b.filter(v2.NewPathRegexFilter(regex))
+}
+
+// WithPropertyFilter filters the last resource by property
+func (b *WatchBuilder) WithPropertyFilter(key string, value string) *WatchBuilder {
+	return b.filter(v2.NewPropertyFilter(key, value))
+}
+
+// WithPathAntPatternsFilter filters the last resource by artifact path with ant patterns
+func (b *WatchBuilder) WithPathAntPatternsFilter(includePatterns []string, excludePatterns []string) *WatchBuilder {
+	return b.filter(v2.NewPathAntPatternsFilter(includePatterns, excludePatterns))
+}
+
+// AssignPolicy assigns a policy of the given type
+func (b *WatchBuilder) AssignPolicy(name string, policyType string) *WatchBuilder {
+	b.policies = append(b.policies, v2.WatchAssignedPolicy{Name: v2.String(name), Type: v2.String(policyType)})
+	return b
+}
+
+// AssignSecurityPolicy assigns a security policy
+func (b *WatchBuilder) AssignSecurityPolicy(name string) *WatchBuilder {
+	return b.AssignPolicy(name, v2.PolicyTypeSecurity)
+}
+
+// AssignLicensePolicy assigns a license policy
+func (b *WatchBuilder) AssignLicensePolicy(name string) *WatchBuilder {
+	return b.AssignPolicy(name, v2.PolicyTypeLicense)
+}
+
+// AssignOperationalRiskPolicy assigns an operational risk policy
+func (b *WatchBuilder) AssignOperationalRiskPolicy(name string) *WatchBuilder {
+	return b.AssignPolicy(name, v2.PolicyTypeOperationalRisk)
+}
+
+// Build returns the watch, or the first misuse of the builder or the validation error of the watch
+func (b *WatchBuilder) Build() (*v2.Watch, error) {
+	if b.err != nil {
+		return nil, fmt.Errorf("invalid watch %q: %s", b.name, b.err.Error())
+	}
+
+	if b.name == "" {
+		return nil, fmt.Errorf("watch name is required")
+	}
+
+	if len(b.resources) == 0 {
+		return nil, fmt.Errorf("invalid watch %q: at least one resource is required", b.name)
+	}
+
+	w := &v2.Watch{
+		GeneralData:      &v2.WatchGeneralData{Name: v2.String(b.name), Active: xray.Bool(b.active)},
+		ProjectResources: v2.NewWatchProjectResources(copyResources(b.resources)...),
+	}
+	if b.description != "" {
+		w.GeneralData.Description = v2.String(b.description)
+	}
+	if len(b.policies) > 0 {
+		policies := make([]v2.WatchAssignedPolicy, len(b.policies))
+		for i, p := range b.policies {
+			policies[i] = v2.WatchAssignedPolicy{Name: copyString(p.Name), Type: copyString(p.Type)}
+		}
+		w.AssignedPolicies = &policies
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid watch %q: %s", b.name, err.Error())
+	}
+
+	return w, nil
+}
+
+func (b *WatchBuilder) resource(r v2.WatchProjectResource) *WatchBuilder {
+	b.resources = append(b.resources, r)
+	return b
+}
+
+func (b *WatchBuilder) filter(f v2.WatchFilter) *WatchBuilder {
+	if r := b.last(*f.Type + " filter"); r != nil {
+		var filters []v2.WatchFilter
+		if r.Filters != nil {
+			filters = *r.Filters
+		}
+		filters = append(filters, f)
+		r.Filters = &filters
+	}
+
+	return b
+}
+
+// last returns the last resource, or records that what needs one was set before any resource
+func (b *WatchBuilder) last(what string) *v2.WatchProjectResource {
+	if len(b.resources) == 0 {
+		if b.err == nil {
+			b.err = fmt.Errorf("%s set before any resource", what)
+		}
+		return nil
+	}
+
+	return &b.resources[len(b.resources)-1]
+}
+
+// copyResources copies the resources and their filters, down to their values and patterns, so that the builder can be
+// reused after Build and the built watch changed without affecting it
+func copyResources(resources []v2.WatchProjectResource) []v2.WatchProjectResource {
+	list := make([]v2.WatchProjectResource, len(resources))
+	for i, r := range resources {
+		r.Type = copyString(r.Type)
+		r.Name = copyString(r.Name)
+		r.BinaryManagerId = copyString(r.BinaryManagerId)
+		r.RepoType = copyString(r.RepoType)
+		if r.Filters != nil {
+			filters := make([]v2.WatchFilter, len(*r.Filters))
+			for j, f := range *r.Filters {
+				f.Type = copyString(f.Type)
+				if f.Value != nil {
+					f.Value = copyFilterValue(*f.Value)
+				}
+				filters[j] = f
+			}
+			r.Filters = &filters
+		}
+		list[i] = r
+	}
+
+	return list
+}
+
+func copyFilterValue(v v2.WatchFilterValueWrapper) *v2.WatchFilterValueWrapper {
+	v.Key = copyString(v.Key)
+	v.Value = copyString(v.Value)
+	if v.AntPatterns != nil {
+		patterns := *v.AntPatterns
+		patterns.IncludePatterns = copyStrings(patterns.IncludePatterns)
+		patterns.ExcludePatterns = copyStrings(patterns.ExcludePatterns)
+		v.AntPatterns = &patterns
+	}
+
+	return &v
+}
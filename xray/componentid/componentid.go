@@ -0,0 +1,300 @@
+// Package componentid parses Xray component ids, e.g. npm://lodash:4.17.21 or gav://org.foo:bar:1.0, and converts them
+// to and from package URLs (purl)
+package componentid
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Component id types, the scheme of the component ids
+const (
+	TypeMaven    = "gav"
+	TypeNpm      = "npm"
+	TypePypi     = "pypi"
+	TypeGo       = "go"
+	TypeDocker   = "docker"
+	TypeNuget    = "nuget"
+	TypeGem      = "gem"
+	TypeComposer = "composer"
+	TypeConan    = "conan"
+	TypeCran     = "cran"
+	TypeCargo    = "cargo"
+	TypeDebian   = "deb"
+	TypeRpm      = "rpm"
+	TypeAlpine   = "alpine"
+	TypeGeneric  = "generic"
+	TypeBuild    = "build"
+)
+
+// purlTypes maps the component id types to package URL types, types without package URL type are not listed
+var purlTypes = map[string]string{
+	TypeMaven:    "maven",
+	TypeNpm:      "npm",
+	TypePypi:     "pypi",
+	TypeGo:       "golang",
+	TypeDocker:   "docker",
+	TypeNuget:    "nuget",
+	TypeGem:      "gem",
+	TypeComposer: "composer",
+	TypeConan:    "conan",
+	TypeCran:     "cran",
+	TypeCargo:    "cargo",
+	TypeDebian:   "deb",
+	TypeRpm:      "rpm",
+	TypeAlpine:   "apk",
+}
+
+// slashNamespaces are the types whose names include a namespace separated by a slash, e.g. @angular/core
+var slashNamespaces = map[string]bool{
+	TypeNpm:      true,
+	TypeGo:       true,
+	TypeDocker:   true,
+	TypeComposer: true,
+}
+
+// ID is a parsed component id
+type ID struct {
+	// Type is the scheme of the component id, see the Type constants
+	Type string
+
+	// Namespace is the Maven group, the npm scope, the path of a Go module or a Docker image up to the last slash, the
+	// Composer vendor or the Debian distribution
+	Namespace string
+
+	Name    string
+	Version string
+
+	// Release is the release of the operating system of deb, rpm and alpine packages, e.g. stretch or 3.9
+	Release string
+}
+
+// Parse parses an Xray component id
+func Parse(s string) (ID, error) {
+	i := strings.Index(s, "://")
+	if i <= 0 {
+		return ID{}, fmt.Errorf("invalid component id %q: missing type", s)
+	}
+
+	id := ID{Type: strings.ToLower(s[:i])}
+	rest := s[i+3:]
+	if rest == "" {
+		return ID{}, fmt.Errorf("invalid component id %q: missing name", s)
+	}
+
+	switch id.Type {
+	case TypeMaven:
+		parts := strings.SplitN(rest, ":", 3)
+		if len(parts) < 2 {
+			return ID{}, fmt.Errorf("invalid component id %q: expected gav://group:artifact:version", s)
+		}
+		id.Namespace, id.Name = parts[0], parts[1]
+		if len(parts) == 3 {
+			id.Version = parts[2]
+		}
+	case TypeDebian:
+		// The version can contain an epoch, e.g. deb://ubuntu:bionic:bash:1:4.4-5
+		parts := strings.SplitN(rest, ":", 4)
+		if len(parts) < 3 {
+			return ID{}, fmt.Errorf("invalid component id %q: expected deb://distribution:release:name:version", s)
+		}
+		id.Namespace, id.Release, id.Name = parts[0], parts[1], parts[2]
+		if len(parts) == 4 {
+			id.Version = parts[3]
+		}
+	case TypeRpm, TypeAlpine:
+		parts := strings.SplitN(rest, ":", 3)
+		if len(parts) < 2 {
+			return ID{}, fmt.Errorf("invalid component id %q: expected %s://release:name:version", s, id.Type)
+		}
+		id.Release, id.Name = parts[0], parts[1]
+		if len(parts) == 3 {
+			id.Version = parts[2]
+		}
+	default:
+		// The version follows the last colon, unless the colon is part of a registry host and port
+		name := rest
+		if j := strings.LastIndex(rest, ":"); j > 0 && !strings.Contains(rest[j:], "/") {
+			name, id.Version = rest[:j], rest[j+1:]
+		}
+
+		id.Name = name
+		if slashNamespaces[id.Type] {
+			if j := strings.LastIndex(name, "/"); j > 0 {
+				id.Namespace, id.Name = name[:j], name[j+1:]
+			}
+		}
+	}
+
+	if id.Name == "" {
+		return ID{}, fmt.Errorf("invalid component id %q: missing name", s)
+	}
+
+	return id, nil
+}
+
+// FullName returns the name including its namespace as Xray reports it, e.g. @angular/core or org.foo:bar
+func (id ID) FullName() string {
+	if id.Namespace == "" {
+		return id.Name
+	}
+
+	switch {
+	case id.Type == TypeMaven:
+		return id.Namespace + ":" + id.Name
+	case slashNamespaces[id.Type]:
+		return id.Namespace + "/" + id.Name
+	default:
+		return id.Name
+	}
+}
+
+// String returns the Xray component id
+func (id ID) String() string {
+	var parts []string
+	switch id.Type {
+	case TypeDebian:
+		parts = []string{id.Namespace, id.Release, id.Name}
+	case TypeRpm, TypeAlpine:
+		parts = []string{id.Release, id.Name}
+	default:
+		parts = []string{id.FullName()}
+	}
+
+	if id.Version != "" {
+		parts = append(parts, id.Version)
+	}
+
+	return id.Type + "://" + strings.Join(parts, ":")
+}
+
+// PURL returns the package URL of the component
+// It returns an error for types that have no package URL type, such as generic and build components
+func (id ID) PURL() (string, error) {
+	typ, ok := purlTypes[id.Type]
+	if !ok {
+		return "", fmt.Errorf("component type %q has no package URL type", id.Type)
+	}
+
+	namespace := id.Namespace
+	qualifiers := url.Values{}
+	switch id.Type {
+	case TypeAlpine:
+		namespace = "alpine"
+		fallthrough
+	case TypeDebian, TypeRpm:
+		if id.Release != "" {
+			qualifiers.Set("distro", id.Release)
+		}
+	}
+
+	s := "pkg:" + typ + "/"
+	if namespace != "" {
+		segments := strings.Split(namespace, "/")
+		for i, segment := range segments {
+			segments[i] = escape(segment)
+		}
+		s += strings.Join(segments, "/") + "/"
+	}
+
+	s += escape(id.Name)
+	if id.Version != "" {
+		s += "@" + escape(id.Version)
+	}
+	if len(qualifiers) > 0 {
+		s += "?" + qualifiers.Encode()
+	}
+
+	return s, nil
+}
+
+// ToPURL converts an Xray component id to a package URL
+func ToPURL(componentId string) (string, error) {
+	id, err := Parse(componentId)
+	if err != nil {
+		return "", err
+	}
+
+	return id.PURL()
+}
+
+// FromPURL parses a package URL into a component, the subpath and the qualifiers other than distro are ignored
+func FromPURL(purl string) (ID, error) {
+	if !strings.HasPrefix(purl, "pkg:") {
+		return ID{}, fmt.Errorf("invalid package URL %q: missing pkg scheme", purl)
+	}
+
+	rest := strings.TrimLeft(strings.TrimPrefix(purl, "pkg:"), "/")
+	if i := strings.Index(rest, "#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	var qualifiers url.Values
+	if i := strings.Index(rest, "?"); i >= 0 {
+		var err error
+		if qualifiers, err = url.ParseQuery(rest[i+1:]); err != nil {
+			return ID{}, fmt.Errorf("invalid package URL %q: %s", purl, err.Error())
+		}
+		rest = rest[:i]
+	}
+
+	var version string
+	if i := strings.LastIndex(rest, "@"); i >= 0 && i > strings.LastIndex(rest, "/") {
+		rest, version = rest[:i], rest[i+1:]
+	}
+
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(segments) < 2 {
+		return ID{}, fmt.Errorf("invalid package URL %q: expected pkg:type/name", purl)
+	}
+
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			return ID{}, fmt.Errorf("invalid package URL %q: %s", purl, err.Error())
+		}
+		segments[i] = unescaped
+	}
+
+	version, err := url.PathUnescape(version)
+	if err != nil {
+		return ID{}, fmt.Errorf("invalid package URL %q: %s", purl, err.Error())
+	}
+
+	typ := strings.ToLower(segments[0])
+	id := ID{
+		Type:      typeOf(typ),
+		Namespace: strings.Join(segments[1:len(segments)-1], "/"),
+		Name:      segments[len(segments)-1],
+		Version:   version,
+	}
+	if id.Type == "" {
+		return ID{}, fmt.Errorf("package URL type %q has no component type", typ)
+	}
+
+	switch id.Type {
+	case TypeAlpine:
+		id.Namespace = ""
+		fallthrough
+	case TypeDebian, TypeRpm:
+		id.Release = qualifiers.Get("distro")
+	}
+
+	return id, nil
+}
+
+func typeOf(purlType string) string {
+	for t, p := range purlTypes {
+		if p == purlType {
+			return t
+		}
+	}
+
+	return ""
+}
+
+// escape percent-encodes a package URL segment, including the characters that separate the parts of a package URL
+func escape(s string) string {
+	return strings.NewReplacer("@", "%40", ":", "%3A").Replace(url.PathEscape(s))
+}
@@ -0,0 +1,115 @@
+package componentid
+
+import (
+	"testing"
+)
+
+var cases = []struct {
+	componentId string
+	id          ID
+	purl        string
+}{
+	{"gav://org.slf4j:slf4j-api:1.7.25", ID{Type: TypeMaven, Namespace: "org.slf4j", Name: "slf4j-api", Version: "1.7.25"}, "pkg:maven/org.slf4j/slf4j-api@1.7.25"},
+	{"npm://lodash:4.17.21", ID{Type: TypeNpm, Name: "lodash", Version: "4.17.21"}, "pkg:npm/lodash@4.17.21"},
+	{"npm://@angular/core:7.2.0", ID{Type: TypeNpm, Namespace: "@angular", Name: "core", Version: "7.2.0"}, "pkg:npm/%40angular/core@7.2.0"},
+	{"pypi://requests:2.20.0", ID{Type: TypePypi, Name: "requests", Version: "2.20.0"}, "pkg:pypi/requests@2.20.0"},
+	{"go://github.com/pkg/errors:v0.8.1", ID{Type: TypeGo, Namespace: "github.com/pkg", Name: "errors", Version: "v0.8.1"}, "pkg:golang/github.com/pkg/errors@v0.8.1"},
+	{"docker://library/nginx:1.15", ID{Type: TypeDocker, Namespace: "library", Name: "nginx", Version: "1.15"}, "pkg:docker/library/nginx@1.15"},
+	{"nuget://Newtonsoft.Json:12.0.1", ID{Type: TypeNuget, Name: "Newtonsoft.Json", Version: "12.0.1"}, "pkg:nuget/Newtonsoft.Json@12.0.1"},
+	{"gem://rails:5.2.2", ID{Type: TypeGem, Name: "rails", Version: "5.2.2"}, "pkg:gem/rails@5.2.2"},
+	{"composer://laravel/framework:5.7.0", ID{Type: TypeComposer, Namespace: "laravel", Name: "framework", Version: "5.7.0"}, "pkg:composer/laravel/framework@5.7.0"},
+	{"deb://debian:stretch:openssl:1.1.0j-1", ID{Type: TypeDebian, Namespace: "debian", Release: "stretch", Name: "openssl", Version: "1.1.0j-1"}, "pkg:deb/debian/openssl@1.1.0j-1?distro=stretch"},
+	{"deb://ubuntu:bionic:bash:1:4.4-5", ID{Type: TypeDebian, Namespace: "ubuntu", Release: "bionic", Name: "bash", Version: "1:4.4-5"}, "pkg:deb/ubuntu/bash@1%3A4.4-5?distro=bionic"},
+	{"rpm://7:openssl-libs:1.0.2k-16.el7", ID{Type: TypeRpm, Release: "7", Name: "openssl-libs", Version: "1.0.2k-16.el7"}, "pkg:rpm/openssl-libs@1.0.2k-16.el7?distro=7"},
+	{"alpine://3.9:musl:1.1.20-r3", ID{Type: TypeAlpine, Release: "3.9", Name: "musl", Version: "1.1.20-r3"}, "pkg:apk/alpine/musl@1.1.20-r3?distro=3.9"},
+}
+
+func TestParse(t *testing.T) {
+	for _, c := range cases {
+		id, err := Parse(c.componentId)
+		if err != nil {
+			t.Errorf("Got the following error for %s: %s", c.componentId, err.Error())
+			continue
+		}
+
+		if id != c.id {
+			t.Errorf("Expected %+v for %s but got: %+v", c.id, c.componentId, id)
+		}
+
+		if id.String() != c.componentId {
+			t.Errorf("Expected String() to return %s but got: %s", c.componentId, id.String())
+		}
+	}
+}
+
+func TestParse_docker_registry(t *testing.T) {
+	id, err := Parse("docker://registry.example.com:5000/team/app")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if id.Namespace != "registry.example.com:5000/team" || id.Name != "app" || id.Version != "" {
+		t.Errorf("Unexpected id: %+v", id)
+	}
+}
+
+func TestParse_invalid(t *testing.T) {
+	for _, s := range []string{"lodash", "npm://", "gav://org.slf4j", "deb://debian:openssl", "://lodash:1.0"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Expected an error for %s", s)
+		}
+	}
+}
+
+func TestPURL(t *testing.T) {
+	for _, c := range cases {
+		purl, err := c.id.PURL()
+		if err != nil {
+			t.Errorf("Got the following error for %s: %s", c.componentId, err.Error())
+			continue
+		}
+
+		if purl != c.purl {
+			t.Errorf("Expected purl %s for %s but got: %s", c.purl, c.componentId, purl)
+		}
+	}
+
+	for _, s := range []string{"generic://sha256:abc/app.zip", "build://app:42"} {
+		if _, err := ToPURL(s); err == nil {
+			t.Errorf("Expected an error for %s", s)
+		}
+	}
+}
+
+func TestFromPURL(t *testing.T) {
+	for _, c := range cases {
+		id, err := FromPURL(c.purl)
+		if err != nil {
+			t.Errorf("Got the following error for %s: %s", c.purl, err.Error())
+			continue
+		}
+
+		if id != c.id {
+			t.Errorf("Expected %+v for %s but got: %+v", c.id, c.purl, id)
+		}
+	}
+}
+
+func TestFromPURL_qualifiers(t *testing.T) {
+	id, err := FromPURL("pkg:deb/debian/curl@7.50.3-1?arch=i386&distro=jessie#usr/bin")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if id.String() != "deb://debian:jessie:curl:7.50.3-1" {
+		t.Errorf("Unexpected component id: %s", id.String())
+	}
+}
+
+func TestFromPURL_invalid(t *testing.T) {
+	for _, s := range []string{"npm://lodash:1.0", "pkg:npm", "pkg:unknown/name@1.0", "pkg:npm/%zz@1.0"} {
+		if _, err := FromPURL(s); err == nil {
+			t.Errorf("Expected an error for %s", s)
+		}
+	}
+}
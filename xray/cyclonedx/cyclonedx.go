@@ -0,0 +1,441 @@
+// Package cyclonedx generates CycloneDX SBOMs from Xray dependency graphs, component details and summaries
+package cyclonedx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/internal/spdxlicense"
+	"github.com/xero-oss/go-xray/internal/uuid"
+	"github.com/xero-oss/go-xray/xray/componentid"
+	"github.com/xero-oss/go-xray/xray/graph"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+const (
+	BomFormat   = "CycloneDX"
+	SpecVersion = "1.4"
+)
+
+// Component types
+const (
+	TypeApplication = "application"
+	TypeContainer   = "container"
+	TypeLibrary     = "library"
+)
+
+type Hash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type License struct {
+	Id   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+	Url  string `json:"url,omitempty"`
+}
+
+type LicenseChoice struct {
+	License License `json:"license"`
+}
+
+type Component struct {
+	Type     string          `json:"type"`
+	BomRef   string          `json:"bom-ref,omitempty"`
+	Group    string          `json:"group,omitempty"`
+	Name     string          `json:"name"`
+	Version  string          `json:"version,omitempty"`
+	Hashes   []Hash          `json:"hashes,omitempty"`
+	Licenses []LicenseChoice `json:"licenses,omitempty"`
+	Purl     string          `json:"purl,omitempty"`
+}
+
+type Tool struct {
+	Vendor string `json:"vendor,omitempty"`
+	Name   string `json:"name"`
+}
+
+type Metadata struct {
+	Timestamp string     `json:"timestamp,omitempty"`
+	Tools     []Tool     `json:"tools,omitempty"`
+	Component *Component `json:"component,omitempty"`
+}
+
+type Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+type VulnerabilitySource struct {
+	Name string `json:"name,omitempty"`
+	Url  string `json:"url,omitempty"`
+}
+
+type Rating struct {
+	Source   *VulnerabilitySource `json:"source,omitempty"`
+	Score    *float64             `json:"score,omitempty"`
+	Severity string               `json:"severity,omitempty"`
+	Method   string               `json:"method,omitempty"`
+}
+
+type Affect struct {
+	Ref string `json:"ref"`
+}
+
+type Vulnerability struct {
+	Id          string               `json:"id"`
+	Source      *VulnerabilitySource `json:"source,omitempty"`
+	Ratings     []Rating             `json:"ratings,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Affects     []Affect             `json:"affects,omitempty"`
+}
+
+// Bom is a CycloneDX document. The converters leave SerialNumber and Metadata.Timestamp empty so that their output is
+// reproducible, see NewSerialNumber
+type Bom struct {
+	BomFormat       string          `json:"bomFormat"`
+	SpecVersion     string          `json:"specVersion"`
+	SerialNumber    string          `json:"serialNumber,omitempty"`
+	Version         int             `json:"version"`
+	Metadata        *Metadata       `json:"metadata,omitempty"`
+	Components      []Component     `json:"components"`
+	Dependencies    []Dependency    `json:"dependencies,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+
+	// graph is the dependency graph the BOM was converted from, it locates the components of impact paths
+	graph *graph.Graph
+}
+
+// Write writes the BOM as indented JSON
+func (b *Bom) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// NewSerialNumber returns a random urn:uuid serial number
+func NewSerialNumber() (string, error) {
+	id, err := uuid.New()
+	if err != nil {
+		return "", err
+	}
+
+	return "urn:uuid:" + id, nil
+}
+
+// fromGraph builds the BOM of a dependency graph, the root of the graph is the metadata component
+func fromGraph(root *Component, g *graph.Graph) *Bom {
+	bom := &Bom{
+		BomFormat:   BomFormat,
+		SpecVersion: SpecVersion,
+		Version:     1,
+		Metadata: &Metadata{
+			Tools:     []Tool{{Vendor: "JFrog", Name: "Xray"}},
+			Component: root,
+		},
+		Components: []Component{},
+		graph:      g,
+	}
+
+	for _, n := range g.Nodes() {
+		if n != g.Root {
+			bom.Components = append(bom.Components, newComponent(n))
+		}
+
+		dependsOn := graph.Ids(n.Dependencies)
+		sort.Strings(dependsOn)
+		bom.Dependencies = append(bom.Dependencies, Dependency{Ref: n.Id, DependsOn: dependsOn})
+	}
+
+	return bom
+}
+
+func newComponent(n *graph.Node) Component {
+	component := Component{
+		Type:    TypeLibrary,
+		BomRef:  n.Id,
+		Name:    n.Name,
+		Version: n.Version,
+		Purl:    purl(n.Id),
+	}
+
+	if id, err := componentid.Parse(n.Id); err == nil {
+		if id.Type == componentid.TypeMaven {
+			component.Group, component.Name = id.Namespace, id.Name
+		}
+		if id.Type == componentid.TypeDocker {
+			component.Type = TypeContainer
+		}
+		if component.Name == "" {
+			component.Name = id.FullName()
+		}
+		if component.Version == "" {
+			component.Version = id.Version
+		}
+	}
+
+	return component
+}
+
+// FromArtifactGraph converts the dependency graph of an artifact, the artifact is the metadata component
+func FromArtifactGraph(output *v1.GetArtifactDependencyGraphOutput) *Bom {
+	root := &Component{Type: TypeApplication}
+	if a := output.Artifact; a != nil {
+		root.BomRef = ptr.StringValue(a.ComponentId)
+		root.Name = ptr.StringValue(a.Name)
+		root.Purl = purl(root.BomRef)
+		if strings.EqualFold(ptr.StringValue(a.PackageType), "docker") {
+			root.Type = TypeContainer
+		}
+		if id, err := componentid.Parse(root.BomRef); err == nil {
+			root.Version = id.Version
+		}
+		if a.Sha256 != nil {
+			root.Hashes = append(root.Hashes, Hash{Alg: "SHA-256", Content: *a.Sha256})
+		}
+		if a.Sha1 != nil {
+			root.Hashes = append(root.Hashes, Hash{Alg: "SHA-1", Content: *a.Sha1})
+		}
+	}
+
+	g := graph.FromArtifactGraph(output)
+	root.BomRef = g.Root.Id
+	return fromGraph(root, g)
+}
+
+// FromBuildGraph converts the dependency graph of a build, the build is the metadata component
+func FromBuildGraph(output *v1.GetBuildDependencyGraphOutput) *Bom {
+	root := &Component{Type: TypeApplication}
+	if b := output.Build; b != nil {
+		root.BomRef = ptr.StringValue(b.ComponentId)
+		root.Name = ptr.StringValue(b.Name)
+		if id, err := componentid.Parse(root.BomRef); err == nil {
+			root.Version = id.Version
+		}
+		if b.Sha256 != nil {
+			root.Hashes = append(root.Hashes, Hash{Alg: "SHA-256", Content: *b.Sha256})
+		}
+	}
+
+	g := graph.FromBuildGraph(output)
+	root.BomRef = g.Root.Id
+	return fromGraph(root, g)
+}
+
+// ComponentGetter is implemented by v1.ComponentsService
+type ComponentGetter interface {
+	GetComponent(ctx context.Context, name string) (*v1.Component, *http.Response, error)
+}
+
+// AddLicenses looks up the details of every component and sets the licenses of its version
+// Components unknown to Xray are left without licenses, other errors stop the lookup
+func (b *Bom) AddLicenses(ctx context.Context, components ComponentGetter) error {
+	for i := range b.Components {
+		c := &b.Components[i]
+
+		details, resp, err := components.GetComponent(ctx, componentName(c.BomRef, c.Version))
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return fmt.Errorf("getting component %s: %s", c.BomRef, err.Error())
+		}
+
+		c.Licenses = licenses(details, c.Version)
+	}
+
+	return nil
+}
+
+// componentName removes the version from a component id, GetComponent returns the details of every version
+func componentName(id string, version string) string {
+	if version == "" {
+		return id
+	}
+
+	return strings.TrimSuffix(id, ":"+version)
+}
+
+func licenses(details *v1.Component, version string) []LicenseChoice {
+	if details == nil || details.Versions == nil {
+		return nil
+	}
+
+	var choices []LicenseChoice
+	for _, v := range *details.Versions {
+		if ptr.StringValue(v.Version) != version || v.Licenses == nil {
+			continue
+		}
+
+		for _, name := range *v.Licenses {
+			if name == "" || strings.EqualFold(name, "unknown") {
+				continue
+			}
+
+			if id, ok := spdxlicense.Id(name); ok {
+				choices = append(choices, LicenseChoice{License: License{Id: id}})
+			} else {
+				choices = append(choices, LicenseChoice{License: License{Name: name}})
+			}
+		}
+	}
+
+	return choices
+}
+
+// AddVulnerabilities adds the security issues of a summary, one vulnerability per CVE or per issue without CVE
+// A vulnerability affects the components at the end of its impact paths, or the summarized artifact when none of them
+// is part of the BOM
+func (b *Bom) AddVulnerabilities(summary *v1.Summary) {
+	if summary == nil || summary.Artifacts == nil {
+		return
+	}
+
+	index := map[string]int{}
+	for i, v := range b.Vulnerabilities {
+		index[v.Id] = i
+	}
+
+	for _, artifact := range *summary.Artifacts {
+		if artifact.Issues == nil {
+			continue
+		}
+
+		var artifactRef string
+		if artifact.General != nil {
+			artifactRef = ptr.StringValue(artifact.General.ComponentId)
+		}
+
+		for _, issue := range *artifact.Issues {
+			if t := ptr.StringValue(issue.IssueType); t != "" && t != "security" {
+				continue
+			}
+
+			affects := b.affected(issue.ImpactPath)
+			if len(affects) == 0 && artifactRef != "" {
+				affects = []Affect{{Ref: artifactRef}}
+			}
+
+			for _, v := range newVulnerabilities(issue) {
+				i, ok := index[v.Id]
+				if !ok {
+					b.Vulnerabilities = append(b.Vulnerabilities, v)
+					i = len(b.Vulnerabilities) - 1
+					index[v.Id] = i
+				}
+
+				b.Vulnerabilities[i].Affects = mergeAffects(b.Vulnerabilities[i].Affects, affects)
+			}
+		}
+	}
+}
+
+// affected returns the components of the BOM found at the end of the impact paths
+func (b *Bom) affected(impactPath *[]string) []Affect {
+	if impactPath == nil || b.graph == nil {
+		return nil
+	}
+
+	var affects []Affect
+	for _, p := range *impactPath {
+		if n, ok := b.graph.NodeByImpactPath(p); ok && n != b.graph.Root {
+			affects = append(affects, Affect{Ref: n.Id})
+		}
+	}
+
+	return affects
+}
+
+func newVulnerabilities(issue v1.SummaryArtifactIssue) []Vulnerability {
+	var vulns []Vulnerability
+
+	newVulnerability := func(id string, cve *v1.SummaryArtifactIssueCve) Vulnerability {
+		v := Vulnerability{Id: id, Description: ptr.StringValue(issue.Summary)}
+		if strings.HasPrefix(strings.ToUpper(id), "CVE-") {
+			v.Source = &VulnerabilitySource{Name: "NVD", Url: "https://nvd.nist.gov/vuln/detail/" + strings.ToUpper(id)}
+		} else if issue.Provider != nil {
+			v.Source = &VulnerabilitySource{Name: *issue.Provider}
+		}
+
+		sev := strings.ToLower(severity.Normalize(ptr.StringValue(issue.Severity)))
+		if sev == "information" {
+			sev = "info"
+		}
+
+		rating := Rating{Severity: sev, Method: "other"}
+		if cve != nil {
+			if score, ok := cvssScore(cve.CvssV3); ok {
+				rating.Score, rating.Method = &score, "CVSSv3"
+			} else if score, ok := cvssScore(cve.CvssV2); ok {
+				rating.Score, rating.Method = &score, "CVSSv2"
+			}
+		}
+		v.Ratings = []Rating{rating}
+
+		return v
+	}
+
+	if issue.Cves != nil {
+		for i := range *issue.Cves {
+			cve := &(*issue.Cves)[i]
+			if ptr.StringValue(cve.Cve) != "" {
+				vulns = append(vulns, newVulnerability(ptr.StringValue(cve.Cve), cve))
+			}
+		}
+	}
+
+	if len(vulns) == 0 && ptr.StringValue(issue.IssueId) != "" {
+		vulns = append(vulns, newVulnerability(ptr.StringValue(issue.IssueId), nil))
+	}
+
+	return vulns
+}
+
+// cvssScore parses the base score of a CVSS value, which Xray reports either as a score or as score/vector
+func cvssScore(v *string) (float64, bool) {
+	if v == nil || *v == "" {
+		return 0, false
+	}
+
+	var score float64
+	if _, err := fmt.Sscanf(strings.SplitN(*v, "/", 2)[0], "%g", &score); err != nil {
+		return 0, false
+	}
+
+	return score, true
+}
+
+func mergeAffects(existing []Affect, affects []Affect) []Affect {
+	for _, a := range affects {
+		found := false
+		for _, e := range existing {
+			if e.Ref == a.Ref {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			existing = append(existing, a)
+		}
+	}
+
+	return existing
+}
+
+// purl returns the package URL of a component id, or an empty string when it has none
+func purl(componentId string) string {
+	p, err := componentid.ToPURL(componentId)
+	if err != nil {
+		return ""
+	}
+
+	return p
+}
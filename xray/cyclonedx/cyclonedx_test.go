@@ -0,0 +1,245 @@
+package cyclonedx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/internal/golden"
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+const graphJson = `{
+  "artifact": {
+    "name": "app-1.0.0.tgz",
+    "path": "default/npm-local/app/-/app-1.0.0.tgz",
+    "pkg_type": "npm",
+    "sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+    "component_id": "npm://app:1.0.0"
+  },
+  "components": [
+    {
+      "component_name": "express",
+      "component_id": "npm://express:4.16.4",
+      "package_type": "npm",
+      "version": "4.16.4",
+      "components": [
+        {"component_name": "lodash", "component_id": "npm://lodash:4.17.4", "package_type": "npm", "version": "4.17.4"}
+      ]
+    },
+    {"component_name": "lodash", "component_id": "npm://lodash:4.17.4", "package_type": "npm", "version": "4.17.4"},
+    {"component_name": "@angular/core", "component_id": "npm://@angular/core:7.2.0", "package_type": "npm", "version": "7.2.0"}
+  ]
+}`
+
+func newBom(t *testing.T) *Bom {
+	var output v1.GetArtifactDependencyGraphOutput
+	if err := json.Unmarshal([]byte(graphJson), &output); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	return FromArtifactGraph(&output)
+}
+
+func TestFromArtifactGraph(t *testing.T) {
+	bom := newBom(t)
+
+	if bom.BomFormat != BomFormat || bom.SpecVersion != SpecVersion {
+		t.Errorf("Expected a CycloneDX %s document but got: %s %s", SpecVersion, bom.BomFormat, bom.SpecVersion)
+	}
+
+	root := bom.Metadata.Component
+	if root.BomRef != "npm://app:1.0.0" || root.Purl != "pkg:npm/app@1.0.0" || len(root.Hashes) != 1 {
+		t.Errorf("Unexpected metadata component: %+v", root)
+	}
+
+	var refs []string
+	for _, c := range bom.Components {
+		refs = append(refs, c.BomRef)
+	}
+	expectedRefs := []string{"npm://express:4.16.4", "npm://lodash:4.17.4", "npm://@angular/core:7.2.0"}
+	if !reflect.DeepEqual(refs, expectedRefs) {
+		t.Errorf("Expected components %v but got: %v", expectedRefs, refs)
+	}
+
+	expectedDependencies := []Dependency{
+		{Ref: "npm://app:1.0.0", DependsOn: []string{"npm://@angular/core:7.2.0", "npm://express:4.16.4", "npm://lodash:4.17.4"}},
+		{Ref: "npm://express:4.16.4", DependsOn: []string{"npm://lodash:4.17.4"}},
+		{Ref: "npm://lodash:4.17.4"},
+		{Ref: "npm://@angular/core:7.2.0"},
+	}
+	if !reflect.DeepEqual(bom.Dependencies, expectedDependencies) {
+		t.Errorf("Expected dependencies %+v but got: %+v", expectedDependencies, bom.Dependencies)
+	}
+}
+
+func TestFromBuildGraph(t *testing.T) {
+	bom := FromBuildGraph(&v1.GetBuildDependencyGraphOutput{
+		Build: &v1.Build{Name: v1.String("app"), ComponentId: v1.String("build://app:42")},
+		Components: &[]v1.GraphComponent{
+			{ComponentId: v1.String("gav://org.slf4j:slf4j-api:1.7.25")},
+		},
+	})
+
+	if bom.Metadata.Component.Version != "42" {
+		t.Errorf("Expected build version 42 but got: %s", bom.Metadata.Component.Version)
+	}
+
+	c := bom.Components[0]
+	if c.Group != "org.slf4j" || c.Name != "slf4j-api" || c.Version != "1.7.25" || c.Purl != "pkg:maven/org.slf4j/slf4j-api@1.7.25" {
+		t.Errorf("Unexpected component: %+v", c)
+	}
+}
+
+func TestAddLicenses(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/lodash"):
+			fmt.Fprint(w, `{"name": "lodash", "versions": [
+				{"version": "4.17.3", "licenses": ["GPL-2.0"]},
+				{"version": "4.17.4", "licenses": ["MIT", "apache-2.0", "Freemarker", "Custom License", "Unknown"]}
+			]}`)
+		case strings.HasSuffix(r.URL.Path, "/express"):
+			fmt.Fprint(w, `{"name": "express", "versions": [{"version": "4.16.4", "licenses": ["MIT"]}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := xray.NewClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	bom := newBom(t)
+	if err := bom.AddLicenses(context.Background(), client.V1.Components); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if len(requested) != 3 {
+		t.Errorf("Expected a lookup per component but got: %v", requested)
+	}
+
+	expected := []LicenseChoice{
+		{License: License{Id: "MIT"}},
+		{License: License{Id: "Apache-2.0"}},
+		{License: License{Name: "Freemarker"}},
+		{License: License{Name: "Custom License"}},
+	}
+	if !reflect.DeepEqual(bom.Components[1].Licenses, expected) {
+		t.Errorf("Expected licenses %+v but got: %+v", expected, bom.Components[1].Licenses)
+	}
+
+	if bom.Components[2].Licenses != nil {
+		t.Errorf("Expected no licenses for an unknown component but got: %+v", bom.Components[2].Licenses)
+	}
+}
+
+func TestAddVulnerabilities(t *testing.T) {
+	bom := newBom(t)
+	bom.AddVulnerabilities(&v1.Summary{
+		Artifacts: &[]v1.SummaryArtifact{
+			{
+				General: &v1.SummaryArtifactGeneral{ComponentId: v1.String("npm://app:1.0.0")},
+				Issues: &[]v1.SummaryArtifactIssue{
+					{
+						IssueType:  v1.String("security"),
+						Severity:   v1.String("High"),
+						Summary:    v1.String("Prototype pollution in lodash"),
+						Cves:       &[]v1.SummaryArtifactIssueCve{{Cve: v1.String("CVE-2018-16487"), CvssV3: v1.String("9.8/CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")}},
+						ImpactPath: &[]string{"default/npm-local/app/-/app-1.0.0.tgz/express:4.16.4/lodash:4.17.4"},
+					},
+					{
+						IssueId:   v1.String("XRAY-1000"),
+						IssueType: v1.String("security"),
+						Severity:  v1.String("Minor"),
+						Provider:  v1.String("JFrog"),
+					},
+					{
+						IssueType: v1.String("license"),
+						Severity:  v1.String("High"),
+					},
+				},
+			},
+		},
+	})
+
+	if len(bom.Vulnerabilities) != 2 {
+		t.Fatalf("Expected 2 vulnerabilities but got: %+v", bom.Vulnerabilities)
+	}
+
+	cve := bom.Vulnerabilities[0]
+	if cve.Id != "CVE-2018-16487" || cve.Source.Name != "NVD" {
+		t.Errorf("Unexpected vulnerability: %+v", cve)
+	}
+	if r := cve.Ratings[0]; r.Severity != "high" || r.Method != "CVSSv3" || r.Score == nil || *r.Score != 9.8 {
+		t.Errorf("Unexpected rating: %+v", r)
+	}
+	if !reflect.DeepEqual(cve.Affects, []Affect{{Ref: "npm://lodash:4.17.4"}}) {
+		t.Errorf("Expected the vulnerability to affect lodash but got: %+v", cve.Affects)
+	}
+
+	issue := bom.Vulnerabilities[1]
+	if issue.Id != "XRAY-1000" || issue.Ratings[0].Severity != "low" || issue.Ratings[0].Score != nil {
+		t.Errorf("Unexpected vulnerability: %+v", issue)
+	}
+	if !reflect.DeepEqual(issue.Affects, []Affect{{Ref: "npm://app:1.0.0"}}) {
+		t.Errorf("Expected the vulnerability to affect the artifact but got: %+v", issue.Affects)
+	}
+}
+
+func TestAddVulnerabilities_golden(t *testing.T) {
+	var graph v1.GetBuildDependencyGraphOutput
+	golden.ReadJSON(t, "testdata/build_graph.json", &graph)
+
+	var summary v1.Summary
+	golden.ReadJSON(t, "testdata/summary.json", &summary)
+
+	bom := FromBuildGraph(&graph)
+	bom.AddVulnerabilities(&summary)
+
+	var buf bytes.Buffer
+	if err := bom.Write(&buf); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/build_vulnerabilities.cdx.json", buf.Bytes())
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newBom(t).Write(&buf); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if doc["bomFormat"] != "CycloneDX" || doc["serialNumber"] != nil {
+		t.Errorf("Unexpected document: %s", buf.String())
+	}
+}
+
+func TestNewSerialNumber(t *testing.T) {
+	serial, err := NewSerialNumber()
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !strings.HasPrefix(serial, "urn:uuid:") || len(serial) != len("urn:uuid:")+36 {
+		t.Errorf("Unexpected serial number: %s", serial)
+	}
+}
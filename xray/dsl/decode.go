@@ -0,0 +1,233 @@
+package dsl
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/xero-oss/go-xray/xray/policylint"
+	"github.com/xero-oss/go-xray/xray/v1"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+// policyDocument is the YAML form of a v1.Policy
+type policyDocument struct {
+	Kind        string         `yaml:"kind"`
+	Name        string         `yaml:"name"`
+	Type        string         `yaml:"type"`
+	Description string         `yaml:"description"`
+	Rules       []ruleDocument `yaml:"rules"`
+}
+
+type ruleDocument struct {
+	Name            string           `yaml:"name"`
+	Priority        *int             `yaml:"priority"`
+	MinSeverity     string           `yaml:"min_severity"`
+	CVSS            *cvssDocument    `yaml:"cvss_range"`
+	BannedLicenses  []string         `yaml:"banned_licenses"`
+	AllowedLicenses []string         `yaml:"allowed_licenses"`
+	AllowUnknown    *bool            `yaml:"allow_unknown"`
+	Actions         *actionsDocument `yaml:"actions"`
+}
+
+type cvssDocument struct {
+	From int `yaml:"from"`
+	To   int `yaml:"to"`
+}
+
+type actionsDocument struct {
+	FailBuild      *bool                  `yaml:"fail_build"`
+	BlockDownload  *blockDownloadDocument `yaml:"block_download"`
+	Mails          []string               `yaml:"mails"`
+	Webhooks       []string               `yaml:"webhooks"`
+	CustomSeverity string                 `yaml:"custom_severity"`
+}
+
+type blockDownloadDocument struct {
+	Active    *bool `yaml:"active"`
+	Unscanned *bool `yaml:"unscanned"`
+}
+
+// watchDocument is the YAML form of a v2.Watch
+type watchDocument struct {
+	Kind        string             `yaml:"kind"`
+	Name        string             `yaml:"name"`
+	Description string             `yaml:"description"`
+	Active      *bool              `yaml:"active"`
+	Resources   []resourceDocument `yaml:"resources"`
+	Policies    []policyReference  `yaml:"policies"`
+}
+
+type resourceDocument struct {
+	Type            string           `yaml:"type"`
+	Name            string           `yaml:"name"`
+	BinaryManagerId string           `yaml:"bin_mgr_id"`
+	RepoType        string           `yaml:"repo_type"`
+	Filters         []filterDocument `yaml:"filters"`
+}
+
+type filterDocument struct {
+	Type    string   `yaml:"type"`
+	Value   string   `yaml:"value"`
+	Key     string   `yaml:"key"`
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+type policyReference struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+// DefaultBinaryManagerId is the binary manager of the watch resources that do not set bin_mgr_id
+const DefaultBinaryManagerId = "default"
+
+func decodePolicy(n *yaml.Node) (*v1.Policy, error) {
+	var doc policyDocument
+	if err := n.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	p := &v1.Policy{Name: v1.String(doc.Name), Type: v1.String(doc.Type)}
+	if doc.Description != "" {
+		p.Description = v1.String(doc.Description)
+	}
+
+	rules := make([]v1.PolicyRule, 0, len(doc.Rules))
+	for i, r := range doc.Rules {
+		// Rules without priority are numbered in order, an explicit priority is kept even when invalid so that it
+		// is reported by policylint
+		priority := i + 1
+		if r.Priority != nil {
+			priority = *r.Priority
+		}
+
+		rule := v1.PolicyRule{
+			Name:     v1.String(r.Name),
+			Priority: &priority,
+			Criteria: &v1.PolicyRuleCriteria{},
+		}
+
+		c := rule.Criteria
+		if r.MinSeverity != "" {
+			c.MinimumSeverity = v1.String(r.MinSeverity)
+		}
+		if r.CVSS != nil {
+			from, to := r.CVSS.From, r.CVSS.To
+			c.CVSSRange = &v1.PolicyCVSSRange{From: &from, To: &to}
+		}
+		if r.BannedLicenses != nil {
+			banned := r.BannedLicenses
+			c.BannedLicenses = &banned
+		}
+		if r.AllowedLicenses != nil {
+			allowed := r.AllowedLicenses
+			c.AllowedLicenses = &allowed
+		}
+		c.AllowUnkown = r.AllowUnknown
+
+		if a := r.Actions; a != nil {
+			actions := &v1.PolicyRuleActions{FailBuild: a.FailBuild}
+			if a.BlockDownload != nil {
+				actions.BlockDownload = &v1.BlockDownloadSettings{Active: a.BlockDownload.Active, Unscanned: a.BlockDownload.Unscanned}
+			}
+			if a.Mails != nil {
+				mails := a.Mails
+				actions.Mails = &mails
+			}
+			if a.Webhooks != nil {
+				webhooks := a.Webhooks
+				actions.Webhooks = &webhooks
+			}
+			if a.CustomSeverity != "" {
+				actions.CustomSeverity = v1.String(a.CustomSeverity)
+			}
+			rule.Actions = actions
+		}
+
+		rules = append(rules, rule)
+	}
+	p.Rules = &rules
+
+	if err := policylint.Err(policylint.Policy(p)); err != nil {
+		return nil, fmt.Errorf("invalid policy %q: %s", doc.Name, err.Error())
+	}
+
+	return p, nil
+}
+
+func decodeWatch(n *yaml.Node) (*v2.Watch, error) {
+	var doc watchDocument
+	if err := n.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	active := true
+	if doc.Active != nil {
+		active = *doc.Active
+	}
+
+	w := &v2.Watch{
+		GeneralData: &v2.WatchGeneralData{Name: v2.String(doc.Name), Active: &active},
+	}
+	if doc.Description != "" {
+		w.GeneralData.Description = v2.String(doc.Description)
+	}
+
+	var resources []v2.WatchProjectResource
+	for _, r := range doc.Resources {
+		binMgrId := r.BinaryManagerId
+		if binMgrId == "" {
+			binMgrId = DefaultBinaryManagerId
+		}
+
+		resource := v2.WatchProjectResource{Type: v2.String(r.Type), BinaryManagerId: v2.String(binMgrId)}
+		if r.Name != "" {
+			resource.Name = v2.String(r.Name)
+		}
+		if r.RepoType != "" {
+			resource.RepoType = v2.String(r.RepoType)
+		}
+
+		var filters []v2.WatchFilter
+		for _, f := range r.Filters {
+			filters = append(filters, newFilter(f))
+		}
+		if len(filters) > 0 {
+			resource.Filters = &filters
+		}
+
+		resources = append(resources, resource)
+	}
+	w.ProjectResources = v2.NewWatchProjectResources(resources...)
+
+	if len(doc.Policies) > 0 {
+		var policies []v2.WatchAssignedPolicy
+		for _, p := range doc.Policies {
+			policies = append(policies, v2.WatchAssignedPolicy{Name: v2.String(p.Name), Type: v2.String(p.Type)})
+		}
+		w.AssignedPolicies = &policies
+	}
+
+	if err := w.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid watch %q: %s", doc.Name, err.Error())
+	}
+
+	return w, nil
+}
+
+func newFilter(f filterDocument) v2.WatchFilter {
+	switch f.Type {
+	case v2.WatchFilterTypeProperty:
+		return v2.NewPropertyFilter(f.Key, f.Value)
+	case v2.WatchFilterTypeAntPatterns:
+		return v2.NewAntPatternsFilter(f.Include, f.Exclude)
+	case v2.WatchFilterTypePathAntPatterns:
+		return v2.NewPathAntPatternsFilter(f.Include, f.Exclude)
+	default:
+		return v2.WatchFilter{
+			Type:  v2.String(f.Type),
+			Value: &v2.WatchFilterValueWrapper{WatchFilterValue: v2.WatchFilterValue{Value: v2.String(f.Value)}},
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	l := NewLoader(map[string]string{"FAIL_BUILD": "true", "TEAM": "platform"})
+
+	p, err := l.LoadPolicy("testdata/policy.yaml")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if *p.Name != "high-severity" || *p.Type != "security" {
+		t.Errorf("Unexpected policy: %s %s", *p.Name, *p.Type)
+	}
+	if *p.Description != "Fails the builds with High issues" {
+		t.Errorf("Expected the default of the variable but got: %s", *p.Description)
+	}
+
+	rules := *p.Rules
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules but got: %d", len(rules))
+	}
+
+	severe := rules[0]
+	if *severe.Priority != 1 || *severe.Criteria.MinimumSeverity != "High" {
+		t.Errorf("Unexpected rule: priority %d, severity %s", *severe.Priority, *severe.Criteria.MinimumSeverity)
+	}
+	if severe.Actions.FailBuild == nil || !*severe.Actions.FailBuild {
+		t.Errorf("Expected the variable to be resolved as a boolean")
+	}
+	if mails := strings.Join(*severe.Actions.Mails, ","); mails != "security@example.com,platform@example.com" {
+		t.Errorf("Expected the included mails but got: %s", mails)
+	}
+
+	cvss := rules[1]
+	if *cvss.Priority != 2 || *cvss.Criteria.CVSSRange.From != 7 || *cvss.Criteria.CVSSRange.To != 10 {
+		t.Errorf("Unexpected CVSS rule: %+v", cvss.Criteria.CVSSRange)
+	}
+	if !*cvss.Actions.BlockDownload.Active || *cvss.Actions.BlockDownload.Unscanned {
+		t.Errorf("Unexpected block download: %+v", cvss.Actions.BlockDownload)
+	}
+	if webhook := (*cvss.Actions.Webhooks)[0]; webhook != "https://hooks.example.com/xray?cost=$5" {
+		t.Errorf("Expected $$ to be escaped but got: %s", webhook)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	config, err := NewLoader(nil).LoadFile("testdata/config.yaml")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if len(config.Policies) != 1 || len(config.Watches) != 1 {
+		t.Fatalf("Expected a policy and a watch but got: %d and %d", len(config.Policies), len(config.Watches))
+	}
+
+	if banned := strings.Join(*(*config.Policies[0].Rules)[0].Criteria.BannedLicenses, ","); banned != "GPL-2.0,GPL-3.0" {
+		t.Errorf("Unexpected banned licenses: %s", banned)
+	}
+
+	w := config.Watches[0]
+	if *w.GeneralData.Name != "releases" || !*w.GeneralData.Active {
+		t.Errorf("Expected an active watch named releases")
+	}
+
+	resources := *w.ProjectResources.Resources
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 resources but got: %d", len(resources))
+	}
+	if *resources[0].BinaryManagerId != DefaultBinaryManagerId || *resources[1].BinaryManagerId != "artifactory" {
+		t.Errorf("Unexpected binary managers: %s and %s", *resources[0].BinaryManagerId, *resources[1].BinaryManagerId)
+	}
+
+	filters := *resources[0].Filters
+	if len(filters) != 2 || *filters[0].Type != "package-type" || *filters[0].Value.Value != "npm" {
+		t.Errorf("Unexpected filters: %+v", filters)
+	}
+	if *filters[1].Type != "property" || *filters[1].Value.Key != "release" || *filters[1].Value.Value != "true" {
+		t.Errorf("Unexpected property filter: %+v", filters[1].Value)
+	}
+
+	if policies := *w.AssignedPolicies; len(policies) != 1 || *policies[0].Name != "licenses" {
+		t.Errorf("Unexpected assigned policies: %+v", policies)
+	}
+}
+
+func TestLoad_errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "unknown kind",
+			data:     "kind: alert\nname: a\n",
+			expected: "test.yaml:1:7: kind: unknown kind \"alert\", expected one of policy, watch",
+		},
+		{
+			name:     "missing kind",
+			data:     "name: a\n",
+			expected: "test.yaml:1:1: document: kind is required",
+		},
+		{
+			name: "schema errors",
+			data: "kind: policy\nname: p\ntype: security\nrules:\n  - name: r\n    priority: first\n    min_severity: Severe\n    colour: red\n",
+			expected: strings.Join([]string{
+				"test.yaml:6:15: rules[0].priority: expected an integer but got \"first\"",
+				"test.yaml:7:19: rules[0].min_severity: unknown value \"Severe\", expected one of Unknown, Information, Low, Medium, High, Critical, Minor, Major",
+				"test.yaml:8:5: rules[0].colour: unknown field, expected one of actions, allow_unknown, allowed_licenses, banned_licenses, cvss_range, min_severity, name, priority",
+			}, "\n"),
+		},
+		{
+			name:     "missing field",
+			data:     "kind: watch\nname: w\n",
+			expected: "test.yaml:1:1: resources: required field is missing",
+		},
+		{
+			name:     "missing variable",
+			data:     "kind: policy\nname: ${NAME}\n",
+			expected: "test.yaml:2:7: variable NAME is not set",
+		},
+		{
+			name:     "invalid policy",
+			data:     "kind: policy\nname: p\ntype: license\nrules:\n  - name: r\n    min_severity: High\n",
+			expected: "test.yaml: invalid policy \"p\": rules[0].criteria: ",
+		},
+		{
+			name:     "variable in key",
+			data:     "kind: policy\nname: p\ntype: security\n${KEY}: x\n",
+			expected: "test.yaml:4:1: ${KEY}: unknown field",
+		},
+		{
+			name:     "zero priority",
+			data:     "kind: policy\nname: p\ntype: security\nrules:\n  - name: r\n    priority: 0\n    min_severity: High\n",
+			expected: "test.yaml: invalid policy \"p\": rules[0].priority: ",
+		},
+		{
+			name:     "invalid watch",
+			data:     "kind: watch\nname: w\nresources:\n  - type: all-repos\n    name: libs\n",
+			expected: "test.yaml: invalid watch \"w\": invalid watch resource 0: resource type \"all-repos\" does not accept a name",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewLoader(nil).Load("test.yaml", []byte(c.data))
+			if err == nil {
+				t.Fatalf("Expected an error")
+			}
+
+			if !strings.HasPrefix(err.Error(), c.expected) {
+				t.Errorf("Expected the error %q but got: %q", c.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoad_includeCycle(t *testing.T) {
+	_, err := NewLoader(nil).LoadFile("testdata/cycle.yaml")
+	if err == nil || !strings.Contains(err.Error(), "includes itself") {
+		t.Errorf("Expected an include cycle error but got: %v", err)
+	}
+}
+
+func TestLoad_env(t *testing.T) {
+	l := &Loader{Vars: map[string]string{"NAME": "from-vars"}, Env: true}
+
+	v, err := l.substitute("${NAME}-${DSL_TEST_UNSET:-default}")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if v != "from-vars-default" {
+		t.Errorf("Expected from-vars-default but got: %s", v)
+	}
+}
+
+func TestLoad_alias(t *testing.T) {
+	data := "kind: policy\nname: p\ntype: security\ndescription: &cost costs $${HOME}\nrules:\n  - name: *cost\n    min_severity: High\n"
+
+	config, err := NewLoader(map[string]string{"HOME": "/root"}).Load("test.yaml", []byte(data))
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	p := config.Policies[0]
+	if *p.Description != "costs ${HOME}" || *(*p.Rules)[0].Name != "costs ${HOME}" {
+		t.Errorf("Expected the anchor and its alias to be substituted once but got: %s and %s", *p.Description, *(*p.Rules)[0].Name)
+	}
+}
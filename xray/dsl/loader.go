@@ -0,0 +1,316 @@
+// Package dsl loads policies and watches from a YAML format meant to be written by hand, with variables and includes,
+// into the v1.Policy and v2.Watch API types
+//
+//	kind: policy
+//	name: high-severity
+//	type: security
+//	rules:
+//	  - name: critical
+//	    min_severity: ${MIN_SEVERITY:-High}
+//	    actions:
+//	      fail_build: true
+//	      mails: !include mails.yaml
+//
+// Variables are written ${NAME} or ${NAME:-default}, $$ is a literal $. They are only substituted in values, so they
+// cannot change the structure of a document. A value tagged !include is replaced by the content of the file, relative
+// to the including file
+package dsl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/xero-oss/go-xray/xray/v1"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+// Kinds of documents
+const (
+	KindPolicy = "policy"
+	KindWatch  = "watch"
+)
+
+// includeTag marks the values replaced by the content of a file
+const includeTag = "!include"
+
+// Config holds the policies and watches of the loaded documents, in order
+type Config struct {
+	Policies []v1.Policy
+	Watches  []v2.Watch
+}
+
+// Loader loads YAML documents
+type Loader struct {
+	// Vars are the values of the variables
+	Vars map[string]string
+
+	// Env looks up the variables missing from Vars in the environment
+	Env bool
+
+	// ReadFile reads the included files, ioutil.ReadFile when nil
+	ReadFile func(path string) ([]byte, error)
+}
+
+// NewLoader creates a Loader with variables
+func NewLoader(vars map[string]string) *Loader {
+	return &Loader{Vars: vars}
+}
+
+// LoadFile loads the documents of a file, see Load
+func (l *Loader) LoadFile(path string) (*Config, error) {
+	data, err := l.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Load(path, data)
+}
+
+// Load loads the policy and watch documents of data, separated by ---. The name is used in errors and to resolve the
+// included files, relative to its directory
+func (l *Loader) Load(name string, data []byte) (*Config, error) {
+	config := &Config{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err.Error())
+		}
+
+		root, err := l.resolve(name, &doc, []string{name}, map[*yaml.Node]*yaml.Node{})
+		if err != nil {
+			return nil, err
+		}
+
+		if root == nil || (root.Kind == yaml.MappingNode && len(root.Content) == 0) {
+			continue
+		}
+
+		if errs := validate(root, documentSchema, ""); len(errs) > 0 {
+			return nil, errs.in(name)
+		}
+
+		switch kind := value(root, "kind").Value; kind {
+		case KindPolicy:
+			p, err := decodePolicy(root)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err.Error())
+			}
+			config.Policies = append(config.Policies, *p)
+		case KindWatch:
+			w, err := decodeWatch(root)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err.Error())
+			}
+			config.Watches = append(config.Watches, *w)
+		}
+	}
+
+	return config, nil
+}
+
+// LoadPolicy loads a file holding a single policy
+func (l *Loader) LoadPolicy(path string) (*v1.Policy, error) {
+	config, err := l.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Policies) != 1 || len(config.Watches) != 0 {
+		return nil, fmt.Errorf("%s: expected a single policy but found %d policies and %d watches", path,
+			len(config.Policies), len(config.Watches))
+	}
+
+	return &config.Policies[0], nil
+}
+
+// LoadWatch loads a file holding a single watch
+func (l *Loader) LoadWatch(path string) (*v2.Watch, error) {
+	config, err := l.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Watches) != 1 || len(config.Policies) != 0 {
+		return nil, fmt.Errorf("%s: expected a single watch but found %d watches and %d policies", path,
+			len(config.Watches), len(config.Policies))
+	}
+
+	return &config.Watches[0], nil
+}
+
+// resolve replaces the includes and substitutes the variables of a node, stack holds the files being included to
+// detect cycles. Nodes are resolved in place, resolved maps them to their result so that the anchors shared by
+// aliases are resolved once
+func (l *Loader) resolve(name string, n *yaml.Node, stack []string, resolved map[*yaml.Node]*yaml.Node) (*yaml.Node, error) {
+	if r, ok := resolved[n]; ok {
+		return r, nil
+	}
+
+	r, err := l.resolveNode(name, n, stack, resolved)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved[n] = r
+	return r, nil
+}
+
+func (l *Loader) resolveNode(name string, n *yaml.Node, stack []string, resolved map[*yaml.Node]*yaml.Node) (*yaml.Node, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return l.resolve(name, n.Content[0], stack, resolved)
+	case yaml.AliasNode:
+		return l.resolve(name, n.Alias, stack, resolved)
+	case yaml.ScalarNode:
+		if n.Tag == includeTag {
+			return l.include(name, n, stack)
+		}
+
+		if strings.Contains(n.Value, "$") {
+			v, err := l.substitute(n.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d:%d: %s", name, n.Line, n.Column, err.Error())
+			}
+
+			if v != n.Value {
+				n.Value = v
+				// Plain values are resolved again, so that a variable can hold a number or a boolean
+				if n.Style == 0 {
+					n.Tag = ""
+				}
+			}
+		}
+		return n, nil
+	default:
+		for i, c := range n.Content {
+			// Variables are only substituted in values, the keys of a mapping are left as written
+			if n.Kind == yaml.MappingNode && i%2 == 0 {
+				continue
+			}
+
+			r, err := l.resolve(name, c, stack, resolved)
+			if err != nil {
+				return nil, err
+			}
+			if r == nil {
+				r = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: c.Line, Column: c.Column}
+			}
+			n.Content[i] = r
+		}
+		return n, nil
+	}
+}
+
+func (l *Loader) include(name string, n *yaml.Node, stack []string) (*yaml.Node, error) {
+	path := n.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(name), path)
+	}
+
+	for _, s := range stack {
+		if s == path {
+			return nil, fmt.Errorf("%s:%d:%d: %s includes itself through %s", name, n.Line, n.Column, path,
+				strings.Join(stack, " -> "))
+		}
+	}
+
+	data, err := l.readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d:%d: %s", name, n.Line, n.Column, err.Error())
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	resolved, err := l.resolve(path, &doc, append(stack, path), map[*yaml.Node]*yaml.Node{})
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved == nil {
+		return nil, fmt.Errorf("%s:%d:%d: %s is empty", name, n.Line, n.Column, path)
+	}
+
+	return resolved, nil
+}
+
+// substitute replaces the variables of a value
+func (l *Loader) substitute(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch {
+		case i+1 < len(s) && s[i+1] == '$':
+			b.WriteByte('$')
+			i++
+		case i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable in %q", s)
+			}
+
+			expr := s[i+2 : i+end]
+			name, def, hasDefault := expr, "", false
+			if j := strings.Index(expr, ":-"); j >= 0 {
+				name, def, hasDefault = expr[:j], expr[j+2:], true
+			}
+
+			v, ok := l.lookup(name)
+			switch {
+			case ok && (v != "" || !hasDefault):
+				b.WriteString(v)
+			case hasDefault:
+				b.WriteString(def)
+			default:
+				return "", fmt.Errorf("variable %s is not set", name)
+			}
+			i += end
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+func (l *Loader) lookup(name string) (string, bool) {
+	if v, ok := l.Vars[name]; ok {
+		return v, true
+	}
+
+	if l.Env {
+		return os.LookupEnv(name)
+	}
+
+	return "", false
+}
+
+func (l *Loader) readFile(path string) ([]byte, error) {
+	if l.ReadFile != nil {
+		return l.ReadFile(path)
+	}
+
+	return ioutil.ReadFile(path)
+}
@@ -0,0 +1,309 @@
+package dsl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+// ValidationError is a value of a document that does not match the schema
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "document"
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, path, e.Message)
+}
+
+// ValidationErrors are all the errors found in a document
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	var messages []string
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// in sets the file of the errors
+func (e ValidationErrors) in(file string) ValidationErrors {
+	for _, err := range e {
+		err.File = file
+	}
+
+	return e
+}
+
+// Scalar types of the schema
+const (
+	typeString = "string"
+	typeInt    = "int"
+	typeBool   = "bool"
+)
+
+// schema describes a value: a scalar of a type, a sequence of items, a mapping with fields, or one of several
+// schemas selected by the value of a field of a mapping
+type schema struct {
+	scalar string
+	enum   []string
+
+	items *schema
+
+	fields   map[string]*schema
+	required []string
+
+	// discriminator is the field selecting the schema of a mapping in variants
+	discriminator string
+	variants      map[string]*schema
+}
+
+func stringSchema(enum ...string) *schema {
+	return &schema{scalar: typeString, enum: enum}
+}
+
+func listOf(items *schema) *schema {
+	return &schema{items: items}
+}
+
+// severities are the severities of Xray, with the legacy Minor and Major names that policylint warns about
+var severities = append(append([]string{}, severity.All...), "Minor", "Major")
+
+var actionsSchema = &schema{fields: map[string]*schema{
+	"fail_build": {scalar: typeBool},
+	"block_download": {fields: map[string]*schema{
+		"active":    {scalar: typeBool},
+		"unscanned": {scalar: typeBool},
+	}},
+	"mails":           listOf(stringSchema()),
+	"webhooks":        listOf(stringSchema()),
+	"custom_severity": stringSchema(severities...),
+}}
+
+var ruleSchema = &schema{
+	fields: map[string]*schema{
+		"name":         stringSchema(),
+		"priority":     {scalar: typeInt},
+		"min_severity": stringSchema(severities...),
+		"cvss_range": {
+			fields:   map[string]*schema{"from": {scalar: typeInt}, "to": {scalar: typeInt}},
+			required: []string{"from", "to"},
+		},
+		"banned_licenses":  listOf(stringSchema()),
+		"allowed_licenses": listOf(stringSchema()),
+		"allow_unknown":    {scalar: typeBool},
+		"actions":          actionsSchema,
+	},
+	required: []string{"name"},
+}
+
+var policySchema = &schema{
+	fields: map[string]*schema{
+		"kind":        stringSchema(KindPolicy),
+		"name":        stringSchema(),
+		"type":        stringSchema(v1.PolicyTypeSecurity, v1.PolicyTypeLicense),
+		"description": stringSchema(),
+		"rules":       listOf(ruleSchema),
+	},
+	required: []string{"kind", "name", "type", "rules"},
+}
+
+var watchSchema = &schema{
+	fields: map[string]*schema{
+		"kind":        stringSchema(KindWatch),
+		"name":        stringSchema(),
+		"description": stringSchema(),
+		"active":      {scalar: typeBool},
+		"resources": listOf(&schema{
+			fields: map[string]*schema{
+				"type": stringSchema(v2.WatchResourceTypeRepository, v2.WatchResourceTypeAllRepos,
+					v2.WatchResourceTypeBuild, v2.WatchResourceTypeAllBuilds, v2.WatchResourceTypeProject,
+					v2.WatchResourceTypeAllProjects, v2.WatchResourceTypeReleaseBundle,
+					v2.WatchResourceTypeAllReleaseBundles),
+				"name":       stringSchema(),
+				"bin_mgr_id": stringSchema(),
+				"repo_type":  stringSchema(),
+				"filters": listOf(&schema{
+					fields: map[string]*schema{
+						"type": stringSchema(v2.WatchFilterTypeRegex, v2.WatchFilterTypePackageType,
+							v2.WatchFilterTypeMimeType, v2.WatchFilterTypeProperty, v2.WatchFilterTypePathRegex,
+							v2.WatchFilterTypePathAntPatterns, v2.WatchFilterTypeAntPatterns),
+						"value":   stringSchema(),
+						"key":     stringSchema(),
+						"include": listOf(stringSchema()),
+						"exclude": listOf(stringSchema()),
+					},
+					required: []string{"type"},
+				}),
+			},
+			required: []string{"type"},
+		}),
+		"policies": listOf(&schema{
+			fields: map[string]*schema{
+				"name": stringSchema(),
+				"type": stringSchema(v1.PolicyTypeSecurity, v1.PolicyTypeLicense, v2.PolicyTypeOperationalRisk),
+			},
+			required: []string{"name", "type"},
+		}),
+	},
+	required: []string{"kind", "name", "resources"},
+}
+
+// documentSchema selects the schema of a document by its kind
+var documentSchema = &schema{
+	discriminator: "kind",
+	variants:      map[string]*schema{KindPolicy: policySchema, KindWatch: watchSchema},
+}
+
+// validate checks a node against a schema and returns every error found
+func validate(n *yaml.Node, s *schema, path string) ValidationErrors {
+	var errs ValidationErrors
+	fail := func(n *yaml.Node, path string, format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{
+			Line:    n.Line,
+			Column:  n.Column,
+			Path:    path,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" {
+		return nil
+	}
+
+	switch {
+	case s.variants != nil:
+		if n.Kind != yaml.MappingNode {
+			fail(n, path, "expected a mapping")
+			break
+		}
+
+		d := value(n, s.discriminator)
+		if d == nil {
+			fail(n, path, "%s is required", s.discriminator)
+			break
+		}
+
+		variant, ok := s.variants[d.Value]
+		if !ok {
+			fail(d, join(path, s.discriminator), "unknown %s %q, expected one of %s", s.discriminator, d.Value,
+				strings.Join(keys(s.variants), ", "))
+			break
+		}
+
+		errs = append(errs, validate(n, variant, path)...)
+	case s.scalar != "":
+		if n.Kind != yaml.ScalarNode {
+			fail(n, path, "expected a %s", s.scalar)
+			break
+		}
+
+		switch tag := n.ShortTag(); {
+		case s.scalar == typeInt && tag != "!!int":
+			fail(n, path, "expected an integer but got %q", n.Value)
+		case s.scalar == typeBool && tag != "!!bool":
+			fail(n, path, "expected true or false but got %q", n.Value)
+		case len(s.enum) > 0 && !containsString(s.enum, n.Value):
+			fail(n, path, "unknown value %q, expected one of %s", n.Value, strings.Join(s.enum, ", "))
+		}
+	case s.items != nil:
+		if n.Kind != yaml.SequenceNode {
+			fail(n, path, "expected a list")
+			break
+		}
+
+		for i, item := range n.Content {
+			errs = append(errs, validate(item, s.items, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	default:
+		if n.Kind != yaml.MappingNode {
+			fail(n, path, "expected a mapping")
+			break
+		}
+
+		seen := map[string]bool{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if seen[k.Value] {
+				fail(k, join(path, k.Value), "duplicate field")
+				continue
+			}
+			seen[k.Value] = true
+
+			field, ok := s.fields[k.Value]
+			if !ok {
+				fail(k, join(path, k.Value), "unknown field, expected one of %s", strings.Join(keys(s.fields), ", "))
+				continue
+			}
+
+			errs = append(errs, validate(v, field, join(path, k.Value))...)
+		}
+
+		for _, r := range s.required {
+			if v := value(n, r); v == nil || v.ShortTag() == "!!null" {
+				fail(n, join(path, r), "required field is missing")
+			}
+		}
+	}
+
+	return errs
+}
+
+// value returns the value of a field of a mapping node, or nil
+func value(n *yaml.Node, field string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == field {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func join(path string, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
+
+func keys(m map[string]*schema) []string {
+	var list []string
+	for k := range m {
+		list = append(list, k)
+	}
+
+	sort.Strings(list)
+	return list
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,243 @@
+// Package export streams Xray violations and license report components to CSV and newline delimited JSON. The writers
+// read the records from paging iterators, so exports of any size only keep a page in memory
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// listSeparator joins the values of list fields in a CSV cell
+const listSeparator = ";"
+
+// ViolationColumn is a CSV column of the violations
+type ViolationColumn struct {
+	Header string
+	Value  func(v v1.Violation) string
+}
+
+// LicenseComponentColumn is a CSV column of the license report components
+type LicenseComponentColumn struct {
+	Header string
+	Value  func(c v1.LicenseReportComponent) string
+}
+
+// ViolationColumns are the available violation columns, keyed by header. See DefaultViolationColumns
+var ViolationColumns = map[string]ViolationColumn{
+	"issue_id":               {"issue_id", func(v v1.Violation) string { return ptr.StringValue(v.IssueId) }},
+	"severity":               {"severity", func(v v1.Violation) string { return ptr.StringValue(v.Severity) }},
+	"type":                   {"type", func(v v1.Violation) string { return ptr.StringValue(v.Type) }},
+	"created":                {"created", func(v v1.Violation) string { return ptr.StringValue(v.Created) }},
+	"watch_name":             {"watch_name", func(v v1.Violation) string { return ptr.StringValue(v.WatchName) }},
+	"description":            {"description", func(v v1.Violation) string { return ptr.StringValue(v.Description) }},
+	"infected_component":     {"infected_component", func(v v1.Violation) string { return list(v.InfectedComponent) }},
+	"impacted_artifacts":     {"impacted_artifacts", func(v v1.Violation) string { return list(v.ImpactedArtifacts) }},
+	"violations_details_url": {"violations_details_url", func(v v1.Violation) string { return ptr.StringValue(v.ViolationDetailsUrl) }},
+}
+
+// DefaultViolationColumns are the headers of the violation columns written when none are given
+var DefaultViolationColumns = []string{
+	"issue_id", "severity", "type", "created", "watch_name", "infected_component", "impacted_artifacts", "description",
+}
+
+// LicenseComponentColumns are the available license report component columns, keyed by header. See
+// DefaultLicenseComponentColumns
+var LicenseComponentColumns = map[string]LicenseComponentColumn{
+	"component_id":   {"component_id", func(c v1.LicenseReportComponent) string { return ptr.StringValue(c.Id) }},
+	"component_name": {"component_name", func(c v1.LicenseReportComponent) string { return ptr.StringValue(c.Name) }},
+	"pkg_type":       {"pkg_type", func(c v1.LicenseReportComponent) string { return ptr.StringValue(c.PackageType) }},
+	"is_root": {"is_root", func(c v1.LicenseReportComponent) string {
+		if c.IsRoot == nil {
+			return ""
+		}
+		return strconv.FormatBool(*c.IsRoot)
+	}},
+	"licenses": {"licenses", func(c v1.LicenseReportComponent) string { return list(c.Licenses) }},
+}
+
+// DefaultLicenseComponentColumns are the headers of the license report component columns written when none are given
+var DefaultLicenseComponentColumns = []string{"component_id", "component_name", "pkg_type", "is_root", "licenses"}
+
+// SelectViolationColumns returns the violation columns with the headers, in order, or the default columns when
+// there are none
+func SelectViolationColumns(headers ...string) ([]ViolationColumn, error) {
+	if len(headers) == 0 {
+		headers = DefaultViolationColumns
+	}
+
+	var columns []ViolationColumn
+	for _, h := range headers {
+		c, ok := ViolationColumns[h]
+		if !ok {
+			return nil, fmt.Errorf("unknown violation column %q", h)
+		}
+		columns = append(columns, c)
+	}
+
+	return columns, nil
+}
+
+// SelectLicenseComponentColumns returns the license report component columns with the headers, in order, or the
+// default columns when there are none
+func SelectLicenseComponentColumns(headers ...string) ([]LicenseComponentColumn, error) {
+	if len(headers) == 0 {
+		headers = DefaultLicenseComponentColumns
+	}
+
+	var columns []LicenseComponentColumn
+	for _, h := range headers {
+		c, ok := LicenseComponentColumns[h]
+		if !ok {
+			return nil, fmt.Errorf("unknown license component column %q", h)
+		}
+		columns = append(columns, c)
+	}
+
+	return columns, nil
+}
+
+// ViolationsCSV writes the violations of the iterator as CSV with a header row and returns the number of violations
+// written. No columns writes the default columns
+func ViolationsCSV(ctx context.Context, w io.Writer, it *ViolationIterator, columns ...ViolationColumn) (int, error) {
+	if len(columns) == 0 {
+		columns, _ = SelectViolationColumns()
+	}
+
+	var header []string
+	for _, c := range columns {
+		header = append(header, c.Header)
+	}
+
+	return writeCSV(w, header, func() ([]string, bool) {
+		if !it.Next(ctx) {
+			return nil, false
+		}
+
+		v := it.Violation()
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = c.Value(v)
+		}
+		return row, true
+	}, it.Err)
+}
+
+// LicenseComponentsCSV writes the components of the iterator as CSV with a header row and returns the number of
+// components written. No columns writes the default columns
+func LicenseComponentsCSV(ctx context.Context, w io.Writer, it *LicenseComponentIterator, columns ...LicenseComponentColumn) (int, error) {
+	if len(columns) == 0 {
+		columns, _ = SelectLicenseComponentColumns()
+	}
+
+	var header []string
+	for _, c := range columns {
+		header = append(header, c.Header)
+	}
+
+	return writeCSV(w, header, func() ([]string, bool) {
+		if !it.Next(ctx) {
+			return nil, false
+		}
+
+		component := it.Component()
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = c.Value(component)
+		}
+		return row, true
+	}, it.Err)
+}
+
+// ViolationsNDJSON writes the violations of the iterator as newline delimited JSON, one violation per line with the
+// fields of the API, and returns the number of violations written
+func ViolationsNDJSON(ctx context.Context, w io.Writer, it *ViolationIterator) (int, error) {
+	return writeNDJSON(w, func() (interface{}, bool) {
+		if !it.Next(ctx) {
+			return nil, false
+		}
+		return it.Violation(), true
+	}, it.Err)
+}
+
+// LicenseComponentsNDJSON writes the components of the iterator as newline delimited JSON, one component per line with
+// the fields of the API, and returns the number of components written
+func LicenseComponentsNDJSON(ctx context.Context, w io.Writer, it *LicenseComponentIterator) (int, error) {
+	return writeNDJSON(w, func() (interface{}, bool) {
+		if !it.Next(ctx) {
+			return nil, false
+		}
+		return it.Component(), true
+	}, it.Err)
+}
+
+// writeCSV writes the rows returned by next until it returns false. The rows written before an iteration error are
+// flushed
+func writeCSV(w io.Writer, header []string, next func() ([]string, bool), iterErr func() error) (int, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for {
+		row, ok := next()
+		if !ok {
+			break
+		}
+
+		if err := cw.Write(row); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return n, err
+	}
+
+	return n, iterErr()
+}
+
+func writeNDJSON(w io.Writer, next func() (interface{}, bool), iterErr func() error) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	n := 0
+	for {
+		v, ok := next()
+		if !ok {
+			break
+		}
+
+		// Encode terminates every value with a newline
+		if err := enc.Encode(v); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	if err := bw.Flush(); err != nil {
+		return n, err
+	}
+
+	return n, iterErr()
+}
+
+func list(values *[]string) string {
+	if values == nil {
+		return ""
+	}
+
+	return strings.Join(*values, listSeparator)
+}
@@ -0,0 +1,264 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+func newViolations(n int) []v1.Violation {
+	var violations []v1.Violation
+	for i := 1; i <= n; i++ {
+		violations = append(violations, v1.Violation{
+			IssueId:           v1.String(fmt.Sprintf("XRAY-%d", i)),
+			Severity:          v1.String("High"),
+			Type:              v1.String("security"),
+			Created:           v1.String(fmt.Sprintf("2019-04-01T10:00:%02dZ", i)),
+			WatchName:         v1.String("prod"),
+			Description:       v1.String(fmt.Sprintf("Issue, \"number\" %d", i)),
+			InfectedComponent: &[]string{"npm://lodash:4.17.4"},
+			ImpactedArtifacts: &[]string{"default/npm-local/app", "default/npm-local/worker"},
+		})
+	}
+
+	return violations
+}
+
+// violationsServer serves the violations page by page and records the pages requested
+func violationsServer(t *testing.T, violations []v1.Violation, pages *[]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/violations" {
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var input v1.GetViolationsInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Fatalf("Got the following error: %s", err.Error())
+		}
+
+		limit := *input.Pagination.Limit
+		page, _ := strconv.Atoi(*input.Pagination.Offset)
+		*pages = append(*pages, page)
+
+		start, end := (page-1)*limit, page*limit
+		if start > len(violations) {
+			start = len(violations)
+		}
+		if end > len(violations) {
+			end = len(violations)
+		}
+
+		output := v1.GetViolationsOutput{TotalViolations: xray.Int(len(violations))}
+		pageViolations := violations[start:end]
+		output.Violations = &pageViolations
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(output)
+	}))
+}
+
+func newClient(t *testing.T, server *httptest.Server) *xray.Xray {
+	client, err := xray.NewClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	return client
+}
+
+func TestViolationIterator(t *testing.T) {
+	var pages []int
+	server := violationsServer(t, newViolations(5), &pages)
+	defer server.Close()
+
+	it := NewViolationIteratorWithPageSize(newClient(t, server).V1.Violations, nil, 2)
+
+	var ids []string
+	for it.Next(context.Background()) {
+		ids = append(ids, *it.Violation().IssueId)
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if strings.Join(ids, ",") != "XRAY-1,XRAY-2,XRAY-3,XRAY-4,XRAY-5" {
+		t.Errorf("Expected the 5 violations in order but got: %v", ids)
+	}
+
+	// The third page is short, no fourth page is requested
+	if fmt.Sprint(pages) != "[1 2 3]" {
+		t.Errorf("Expected pages 1 to 3 to be requested but got: %v", pages)
+	}
+}
+
+func TestViolationIterator_exactPages(t *testing.T) {
+	var pages []int
+	server := violationsServer(t, newViolations(4), &pages)
+	defer server.Close()
+
+	it := NewViolationIteratorWithPageSize(newClient(t, server).V1.Violations, nil, 2)
+
+	n := 0
+	for it.Next(context.Background()) {
+		n++
+	}
+
+	if n != 4 {
+		t.Errorf("Expected 4 violations but got: %d", n)
+	}
+
+	// The total reported by Xray stops the iteration without requesting an empty page
+	if fmt.Sprint(pages) != "[1 2]" {
+		t.Errorf("Expected pages 1 and 2 to be requested but got: %v", pages)
+	}
+}
+
+func TestViolationIterator_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	it := NewViolationIterator(newClient(t, server).V1.Violations, nil)
+	if it.Next(context.Background()) {
+		t.Errorf("Expected no violation")
+	}
+
+	if it.Err() == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestViolationsCSV(t *testing.T) {
+	var pages []int
+	server := violationsServer(t, newViolations(3), &pages)
+	defer server.Close()
+
+	columns, err := SelectViolationColumns("issue_id", "severity", "description", "impacted_artifacts")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	it := NewViolationIteratorWithPageSize(newClient(t, server).V1.Violations, nil, 2)
+	n, err := ViolationsCSV(context.Background(), &buf, it, columns...)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if n != 3 {
+		t.Errorf("Expected 3 violations but got: %d", n)
+	}
+
+	expected := `issue_id,severity,description,impacted_artifacts
+XRAY-1,High,"Issue, ""number"" 1",default/npm-local/app;default/npm-local/worker
+XRAY-2,High,"Issue, ""number"" 2",default/npm-local/app;default/npm-local/worker
+XRAY-3,High,"Issue, ""number"" 3",default/npm-local/app;default/npm-local/worker
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
+
+func TestViolationsNDJSON(t *testing.T) {
+	var pages []int
+	server := violationsServer(t, newViolations(3), &pages)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	it := NewViolationIteratorWithPageSize(newClient(t, server).V1.Violations, nil, 2)
+	n, err := ViolationsNDJSON(context.Background(), &buf, it)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if n != 3 || len(lines) != 3 {
+		t.Fatalf("Expected 3 lines but got: %d", len(lines))
+	}
+
+	var v v1.Violation
+	if err := json.Unmarshal([]byte(lines[2]), &v); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if *v.IssueId != "XRAY-3" {
+		t.Errorf("Expected XRAY-3 but got: %s", *v.IssueId)
+	}
+}
+
+func TestSelectColumns_unknown(t *testing.T) {
+	if _, err := SelectViolationColumns("issue_id", "nope"); err == nil {
+		t.Errorf("Expected an error for an unknown violation column")
+	}
+
+	if _, err := SelectLicenseComponentColumns("nope"); err == nil {
+		t.Errorf("Expected an error for an unknown license component column")
+	}
+}
+
+func TestLicenseComponentsCSV(t *testing.T) {
+	components := []v1.LicenseReportComponent{
+		{Id: v1.String("npm://lodash:4.17.4"), Name: v1.String("lodash"), PackageType: v1.String("npm"), IsRoot: xray.Bool(false), Licenses: &[]string{"MIT"}},
+		{Id: v1.String("npm://app:42"), Name: v1.String("app"), PackageType: v1.String("npm"), IsRoot: xray.Bool(true), Licenses: &[]string{"MIT", "Apache-2.0"}},
+		{Id: v1.String("npm://left-pad:1.3.0"), Name: v1.String("left-pad"), PackageType: v1.String("npm")},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v1/licensesReport/components" || q.Get("license") != "MIT" {
+			t.Errorf("Unexpected request: %s", r.URL.String())
+		}
+
+		rows, _ := strconv.Atoi(q.Get("num_of_rows"))
+		page, _ := strconv.Atoi(q.Get("page_num"))
+		start, end := (page-1)*rows, page*rows
+		if start > len(components) {
+			start = len(components)
+		}
+		if end > len(components) {
+			end = len(components)
+		}
+
+		data := components[start:end]
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v1.GetLicenseReportComponentsOutput{Data: &data, TotalCount: xray.Int(len(components))})
+	}))
+	defer server.Close()
+
+	it := NewLicenseComponentIterator(newClient(t, server).V1.Reports, v1.GetLicenseReportComponentsInput{
+		License:      v1.String("MIT"),
+		NumberOfRows: xray.Int(2),
+	})
+
+	var buf bytes.Buffer
+	n, err := LicenseComponentsCSV(context.Background(), &buf, it)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if n != 3 {
+		t.Errorf("Expected 3 components but got: %d", n)
+	}
+
+	expected := `component_id,component_name,pkg_type,is_root,licenses
+npm://lodash:4.17.4,lodash,npm,false,MIT
+npm://app:42,app,npm,true,MIT;Apache-2.0
+npm://left-pad:1.3.0,left-pad,npm,,
+`
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/xero-oss/go-xray/internal/paging"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// DefaultPageSize is the number of records requested per page when the PageSize of an iterator is not set
+const DefaultPageSize = paging.DefaultPageSize
+
+// ViolationsGetter is implemented by v1.ViolationsService
+type ViolationsGetter interface {
+	GetViolations(ctx context.Context, getViolationsInput *v1.GetViolationsInput) (*v1.GetViolationsOutput, *http.Response, error)
+}
+
+// LicenseComponentsGetter is implemented by v1.ReportsService
+type LicenseComponentsGetter interface {
+	GetLicenseReportComponents(ctx context.Context, getLicenseReportComponentsInput *v1.GetLicenseReportComponentsInput) (*v1.GetLicenseReportComponentsOutput, *http.Response, error)
+}
+
+// ViolationIterator iterates over the violations matching filters, page by page
+//
+//	it := export.NewViolationIterator(client.V1.Violations, filters)
+//	for it.Next(ctx) {
+//		v := it.Violation()
+//	}
+//	err := it.Err()
+type ViolationIterator = paging.ViolationIterator
+
+// NewViolationIterator creates an iterator over the violations matching filters, which can be nil, in creation order
+func NewViolationIterator(violations ViolationsGetter, filters *v1.GetViolationsFilters) *ViolationIterator {
+	return NewViolationIteratorWithPageSize(violations, filters, DefaultPageSize)
+}
+
+// NewViolationIteratorWithPageSize creates an iterator requesting pageSize violations per page
+func NewViolationIteratorWithPageSize(violations ViolationsGetter, filters *v1.GetViolationsFilters, pageSize int) *ViolationIterator {
+	return paging.NewViolationIterator(violations, filters, pageSize)
+}
+
+// LicenseComponentIterator iterates over the components of the license report, page by page
+type LicenseComponentIterator struct {
+	components LicenseComponentsGetter
+	input      v1.GetLicenseReportComponentsInput
+	pager      paging.Pager
+	current    []v1.LicenseReportComponent
+}
+
+// NewLicenseComponentIterator creates an iterator over the components of the license report matching the License or
+// Compliance of the input, its NumberOfRows is the page size and its PageNumber is managed by the iterator
+func NewLicenseComponentIterator(components LicenseComponentsGetter, input v1.GetLicenseReportComponentsInput) *LicenseComponentIterator {
+	pageSize := DefaultPageSize
+	if input.NumberOfRows != nil {
+		pageSize = *input.NumberOfRows
+	}
+
+	return &LicenseComponentIterator{components: components, input: input, pager: paging.New(pageSize)}
+}
+
+// Next advances to the next component and returns false at the end of the components or on error, see Err
+func (it *LicenseComponentIterator) Next(ctx context.Context) bool {
+	return it.pager.Next(ctx, func(ctx context.Context, page int, pageSize int) (int, *int, error) {
+		input := it.input
+		input.NumberOfRows = &pageSize
+		input.PageNumber = &page
+
+		output, _, err := it.components.GetLicenseReportComponents(ctx, &input)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		it.current = nil
+		if output.Data != nil {
+			it.current = *output.Data
+		}
+
+		return len(it.current), output.TotalCount, nil
+	})
+}
+
+// Component returns the current component
+func (it *LicenseComponentIterator) Component() v1.LicenseReportComponent {
+	return it.current[it.pager.Index()]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *LicenseComponentIterator) Err() error {
+	return it.pager.Err()
+}
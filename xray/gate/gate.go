@@ -0,0 +1,287 @@
+// Package gate decides whether a CI build passes based on its Xray build scan
+package gate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// Outcome is the result of a gate, see ExitCode
+type Outcome string
+
+// Outcomes of a gate, in increasing order of precedence
+const (
+	OutcomePass              Outcome = "pass"
+	OutcomeMaxAlerts         Outcome = "max_alerts"
+	OutcomeSeverityThreshold Outcome = "severity_threshold"
+	OutcomeFailBuild         Outcome = "fail_build"
+	OutcomeTimeout           Outcome = "timeout"
+	OutcomeError             Outcome = "error"
+)
+
+var exitCodes = map[Outcome]int{
+	OutcomePass:              0,
+	OutcomeError:             1,
+	OutcomeFailBuild:         3,
+	OutcomeSeverityThreshold: 4,
+	OutcomeMaxAlerts:         5,
+	OutcomeTimeout:           6,
+}
+
+// ExitCode returns the process exit code of the outcome. 2 is left for usage errors
+func (o Outcome) ExitCode() int {
+	return exitCodes[o]
+}
+
+const (
+	// DefaultTimeout is how long Check waits for the scan results when Gate.Timeout is not set
+	DefaultTimeout = 10 * time.Minute
+
+	// DefaultInterval is the time between two scan attempts when Gate.Interval is not set
+	DefaultInterval = 10 * time.Second
+)
+
+// TimeoutError is returned when Xray has not scanned the build before the timeout expires
+type TimeoutError struct {
+	Build   string
+	Timeout time.Duration
+
+	// Err is the last error returned while waiting
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s waiting for the scan results of build %s: %s", e.Timeout, e.Build, e.Err.Error())
+}
+
+// BuildScanner is implemented by v1.ScanningService
+type BuildScanner interface {
+	ScanBuild(ctx context.Context, scanBuildInput *v1.ScanBuildInput) (*v1.ScanBuildOutput, *http.Response, error)
+}
+
+// Thresholds are the local rules applied on top of the policies evaluated by Xray
+type Thresholds struct {
+	// MinimumSeverity fails the gate when an issue is at least this severe, empty disables the check
+	MinimumSeverity string `json:"min_severity,omitempty"`
+
+	// AllowedCVEs lists the CVEs or issue IDs that are ignored by every check
+	AllowedCVEs []string `json:"allowed_cves,omitempty"`
+
+	// MaxAlerts fails the gate when there are more issues than this, nil disables the check
+	MaxAlerts *int `json:"max_alerts,omitempty"`
+
+	// IgnoreFailBuild does not fail the gate when Xray requests the build to fail
+	IgnoreFailBuild bool `json:"ignore_fail_build,omitempty"`
+}
+
+// Finding is an issue of the build scan
+type Finding struct {
+	Watch     string   `json:"watch,omitempty"`
+	Severity  string   `json:"severity,omitempty"`
+	Type      string   `json:"type,omitempty"`
+	Id        string   `json:"id,omitempty"`
+	Summary   string   `json:"summary,omitempty"`
+	Artifacts []string `json:"artifacts,omitempty"`
+}
+
+// Verdict is the structured result of a gate
+type Verdict struct {
+	Outcome        Outcome   `json:"outcome"`
+	ExitCode       int       `json:"exit_code"`
+	Reasons        []string  `json:"reasons,omitempty"`
+	Build          string    `json:"build,omitempty"`
+	MoreDetailsUrl string    `json:"more_details_url,omitempty"`
+	Findings       []Finding `json:"findings,omitempty"`
+	Allowed        []Finding `json:"allowed,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// Gate scans builds and evaluates the results against thresholds
+type Gate struct {
+	Scanner    BuildScanner
+	Thresholds Thresholds
+
+	// Timeout bounds the time spent waiting for Xray to scan the build
+	Timeout time.Duration
+
+	// Interval is the time between two scan attempts while the build is not scanned yet
+	Interval time.Duration
+}
+
+// New creates a Gate scanning with client.V1.Scanning
+func New(scanner BuildScanner, thresholds Thresholds) *Gate {
+	return &Gate{
+		Scanner:    scanner,
+		Thresholds: thresholds,
+		Timeout:    DefaultTimeout,
+		Interval:   DefaultInterval,
+	}
+}
+
+// Check scans the build, retrying until Xray returns the results or the timeout expires, and evaluates them
+// Failures to scan are reported as OutcomeError in the verdict, or OutcomeTimeout when the timeout expired
+func (g *Gate) Check(ctx context.Context, input *v1.ScanBuildInput) *Verdict {
+	output, err := g.scan(ctx, input)
+	if err != nil {
+		v := &Verdict{Outcome: OutcomeError, Error: err.Error(), Reasons: []string{"the build could not be scanned"}}
+		if _, ok := err.(*TimeoutError); ok {
+			v.Outcome = OutcomeTimeout
+			v.Reasons = []string{"the build was not scanned in time"}
+		}
+		v.ExitCode = v.Outcome.ExitCode()
+		v.Build = buildName(input)
+		return v
+	}
+
+	v := Evaluate(output, g.Thresholds)
+	v.Build = buildName(input)
+	return v
+}
+
+func (g *Gate) scan(ctx context.Context, input *v1.ScanBuildInput) (*v1.ScanBuildOutput, error) {
+	timeout := g.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	interval := g.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		output, resp, err := g.Scanner.ScanBuild(ctx, input)
+		if err == nil {
+			return output, nil
+		}
+
+		// The request itself fails once the deadline is exceeded
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &TimeoutError{Build: buildName(input), Timeout: timeout, Err: err}
+		}
+
+		// Only retry while Xray reports that the build is not available yet
+		if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode < 500) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, &TimeoutError{Build: buildName(input), Timeout: timeout, Err: err}
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Evaluate applies the thresholds to the results of a build scan
+func Evaluate(output *v1.ScanBuildOutput, t Thresholds) *Verdict {
+	allowed := map[string]bool{}
+	for _, id := range t.AllowedCVEs {
+		allowed[strings.ToUpper(id)] = true
+	}
+
+	v := &Verdict{Outcome: OutcomePass}
+	if output.Summary != nil && output.Summary.MoreDetailsUrl != nil {
+		v.MoreDetailsUrl = *output.Summary.MoreDetailsUrl
+	}
+
+	if output.Alerts != nil {
+		for _, alert := range *output.Alerts {
+			if alert.Issues == nil {
+				continue
+			}
+
+			for _, issue := range *alert.Issues {
+				f := newFinding(alert, issue)
+				if f.Id != "" && allowed[strings.ToUpper(f.Id)] {
+					v.Allowed = append(v.Allowed, f)
+				} else {
+					v.Findings = append(v.Findings, f)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(v.Findings, func(i, j int) bool {
+		return severity.Rank(v.Findings[i].Severity) > severity.Rank(v.Findings[j].Severity)
+	})
+
+	failBuild := output.Summary != nil && output.Summary.FailBuild != nil && *output.Summary.FailBuild
+	if failBuild && !t.IgnoreFailBuild && len(v.Findings) > 0 {
+		v.fail(OutcomeFailBuild, "Xray policies require the build to fail")
+	}
+
+	if t.MinimumSeverity != "" {
+		count := 0
+		for _, f := range v.Findings {
+			if severity.AtLeast(f.Severity, t.MinimumSeverity) {
+				count++
+			}
+		}
+		if count > 0 {
+			v.fail(OutcomeSeverityThreshold, fmt.Sprintf("%d issue(s) with severity %s or higher", count, severity.Normalize(t.MinimumSeverity)))
+		}
+	}
+
+	if t.MaxAlerts != nil && len(v.Findings) > *t.MaxAlerts {
+		v.fail(OutcomeMaxAlerts, fmt.Sprintf("%d issue(s), more than the %d allowed", len(v.Findings), *t.MaxAlerts))
+	}
+
+	v.ExitCode = v.Outcome.ExitCode()
+	return v
+}
+
+// fail records a reason and keeps the outcome with the highest precedence
+func (v *Verdict) fail(outcome Outcome, reason string) {
+	v.Reasons = append(v.Reasons, reason)
+	if precedence(outcome) > precedence(v.Outcome) {
+		v.Outcome = outcome
+	}
+}
+
+func precedence(o Outcome) int {
+	for i, candidate := range []Outcome{OutcomePass, OutcomeMaxAlerts, OutcomeSeverityThreshold, OutcomeFailBuild, OutcomeTimeout, OutcomeError} {
+		if candidate == o {
+			return i
+		}
+	}
+
+	return 0
+}
+
+func newFinding(alert v1.BuileScanAlert, issue v1.BuildScanIssue) Finding {
+	f := Finding{
+		Watch:    ptr.StringValue(alert.WatchName),
+		Severity: ptr.StringValue(issue.Severity),
+		Type:     ptr.StringValue(issue.Type),
+		Id:       ptr.StringValue(issue.CVE),
+		Summary:  ptr.StringValue(issue.Summary),
+	}
+
+	if issue.ImpactedArtifacts != nil {
+		for _, a := range *issue.ImpactedArtifacts {
+			name := ptr.StringValue(a.DisplayName)
+			if name == "" {
+				name = ptr.StringValue(a.Name)
+			}
+			f.Artifacts = append(f.Artifacts, name)
+		}
+	}
+
+	return f
+}
+
+func buildName(input *v1.ScanBuildInput) string {
+	return ptr.StringValue(input.BuildName) + "/" + ptr.StringValue(input.BuildNumber)
+}
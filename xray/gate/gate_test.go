@@ -0,0 +1,150 @@
+package gate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+const scanOutput = `{
+	"summary": {"fail_build": true, "total_alerts": 2, "more_details_url": "https://xray/builds/app/1"},
+	"alerts": [{
+		"watch_name": "prod",
+		"top_severity": "High",
+		"issues": [
+			{"cve": "CVE-2018-16487", "severity": "High", "type": "security", "summary": "Prototype pollution",
+			 "impacted_artifacts": [{"display_name": "lodash:4.17.10"}]},
+			{"cve": "CVE-2019-0001", "severity": "Low", "type": "security", "summary": "Minor issue"}
+		]
+	}]
+}`
+
+func newScanOutput(t *testing.T) *v1.ScanBuildOutput {
+	output := new(v1.ScanBuildOutput)
+	if err := json.Unmarshal([]byte(scanOutput), output); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	return output
+}
+
+func TestEvaluate_outcomes(t *testing.T) {
+	zero := 0
+	one := 1
+
+	cases := []struct {
+		name       string
+		thresholds Thresholds
+		outcome    Outcome
+	}{
+		{"xray fail build", Thresholds{}, OutcomeFailBuild},
+		{"fail build ignored", Thresholds{IgnoreFailBuild: true}, OutcomePass},
+		{"severity threshold", Thresholds{IgnoreFailBuild: true, MinimumSeverity: "high"}, OutcomeSeverityThreshold},
+		{"allowlisted cve", Thresholds{IgnoreFailBuild: true, MinimumSeverity: "High", AllowedCVEs: []string{"cve-2018-16487"}}, OutcomePass},
+		{"max alerts", Thresholds{IgnoreFailBuild: true, MaxAlerts: &one}, OutcomeMaxAlerts},
+		{"all allowlisted", Thresholds{AllowedCVEs: []string{"CVE-2018-16487", "CVE-2019-0001"}, MaxAlerts: &zero}, OutcomePass},
+	}
+
+	for _, c := range cases {
+		v := Evaluate(newScanOutput(t), c.thresholds)
+		if v.Outcome != c.outcome {
+			t.Errorf("%s: expected outcome %s but got: %s (%v)", c.name, c.outcome, v.Outcome, v.Reasons)
+		}
+
+		if v.ExitCode != c.outcome.ExitCode() {
+			t.Errorf("%s: expected exit code %d but got: %d", c.name, c.outcome.ExitCode(), v.ExitCode)
+		}
+	}
+}
+
+func TestOutcomeExitCode_distinct(t *testing.T) {
+	seen := map[int]Outcome{}
+	for _, o := range []Outcome{OutcomePass, OutcomeMaxAlerts, OutcomeSeverityThreshold, OutcomeFailBuild, OutcomeTimeout, OutcomeError} {
+		if other, ok := seen[o.ExitCode()]; ok {
+			t.Errorf("Expected distinct exit codes but %s and %s share %d", o, other, o.ExitCode())
+		}
+		seen[o.ExitCode()] = o
+	}
+}
+
+// blockingScanner waits for the request to be cancelled
+type blockingScanner struct{}
+
+func (blockingScanner) ScanBuild(ctx context.Context, input *v1.ScanBuildInput) (*v1.ScanBuildOutput, *http.Response, error) {
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+type fakeScanner struct {
+	notReady int
+}
+
+func (f *fakeScanner) ScanBuild(ctx context.Context, input *v1.ScanBuildInput) (*v1.ScanBuildOutput, *http.Response, error) {
+	if f.notReady > 0 {
+		f.notReady--
+		return nil, &http.Response{StatusCode: http.StatusNotFound}, fmt.Errorf("build not found")
+	}
+
+	output := new(v1.ScanBuildOutput)
+	err := json.Unmarshal([]byte(scanOutput), output)
+	return output, &http.Response{StatusCode: http.StatusOK}, err
+}
+
+func TestGateCheck_waitsForResults(t *testing.T) {
+	g := New(&fakeScanner{notReady: 2}, Thresholds{})
+	g.Interval = time.Millisecond
+
+	v := g.Check(context.Background(), &v1.ScanBuildInput{BuildName: v1.String("app"), BuildNumber: v1.String("1")})
+	if v.Outcome != OutcomeFailBuild {
+		t.Errorf("Expected outcome fail_build but got: %s (%s)", v.Outcome, v.Error)
+	}
+
+	var buf bytes.Buffer
+	if err := v.WriteSummary(&buf); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	for _, expected := range []string{"FAILED for build app/1", "[High] security Prototype pollution (CVE-2018-16487) in lodash:4.17.10", "More details: https://xray/builds/app/1"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected the summary to contain '%s' but got:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestGateCheck_timeout(t *testing.T) {
+	g := New(&fakeScanner{notReady: 1000}, Thresholds{})
+	g.Interval = time.Millisecond
+	g.Timeout = 20 * time.Millisecond
+
+	v := g.Check(context.Background(), &v1.ScanBuildInput{BuildName: v1.String("app"), BuildNumber: v1.String("1")})
+	if v.Outcome != OutcomeTimeout || v.ExitCode != OutcomeTimeout.ExitCode() {
+		t.Errorf("Expected outcome timeout but got: %s (exit code %d)", v.Outcome, v.ExitCode)
+	}
+
+	if !strings.HasPrefix(v.Error, "timed out after 20ms waiting for the scan results of build app/1: ") {
+		t.Errorf("Unexpected error: %s", v.Error)
+	}
+}
+
+func TestGateCheck_requestTimeout(t *testing.T) {
+	g := New(blockingScanner{}, Thresholds{})
+	g.Timeout = 20 * time.Millisecond
+
+	_, err := g.scan(context.Background(), &v1.ScanBuildInput{BuildName: v1.String("app"), BuildNumber: v1.String("1")})
+
+	timeoutErr, ok := err.(*TimeoutError)
+	if !ok {
+		t.Fatalf("Expected a timeout error but got: %v", err)
+	}
+
+	if timeoutErr.Build != "app/1" || timeoutErr.Err != context.DeadlineExceeded {
+		t.Errorf("Unexpected timeout error: %+v", timeoutErr)
+	}
+}
@@ -0,0 +1,55 @@
+package gate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxSummaryFindings is the number of findings listed by WriteSummary
+const maxSummaryFindings = 20
+
+// WriteSummary writes a human readable summary of the verdict
+func (v *Verdict) WriteSummary(w io.Writer) error {
+	var b strings.Builder
+
+	status := "PASSED"
+	if v.Outcome != OutcomePass {
+		status = "FAILED"
+	}
+
+	fmt.Fprintf(&b, "Xray gate %s for build %s (%s)\n", status, v.Build, v.Outcome)
+
+	if v.Error != "" {
+		fmt.Fprintf(&b, "  error: %s\n", v.Error)
+	}
+
+	for _, r := range v.Reasons {
+		fmt.Fprintf(&b, "  - %s\n", r)
+	}
+
+	fmt.Fprintf(&b, "%d issue(s), %d allowed\n", len(v.Findings), len(v.Allowed))
+
+	for i, f := range v.Findings {
+		if i == maxSummaryFindings {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(v.Findings)-maxSummaryFindings)
+			break
+		}
+
+		fmt.Fprintf(&b, "  [%s] %s %s", f.Severity, f.Type, f.Summary)
+		if f.Id != "" {
+			fmt.Fprintf(&b, " (%s)", f.Id)
+		}
+		if len(f.Artifacts) > 0 {
+			fmt.Fprintf(&b, " in %s", strings.Join(f.Artifacts, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	if v.MoreDetailsUrl != "" {
+		fmt.Fprintf(&b, "More details: %s\n", v.MoreDetailsUrl)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,273 @@
+// Package graph flattens the dependency trees returned by Xray into a graph that can be traversed and queried
+package graph
+
+import (
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// Node is a component of the graph. A component used by several others is a single node with several dependents
+type Node struct {
+	Id          string
+	Name        string
+	PackageType string
+	Version     string
+
+	// Dependencies are the direct dependencies of the component, in order of first appearance
+	Dependencies []*Node
+
+	// Dependents are the components that depend directly on the component
+	Dependents []*Node
+}
+
+// Graph is a dependency graph rooted at an artifact or a build
+type Graph struct {
+	Root *Node
+
+	nodes map[string]*Node
+	order []*Node
+}
+
+// New builds the graph of a root component and its dependency trees. Components are identified by their component id,
+// the dependencies of every occurrence of a component are merged
+func New(root *Node, components *[]v1.GraphComponent) *Graph {
+	g := &Graph{
+		Root:  root,
+		nodes: map[string]*Node{root.Id: root},
+		order: []*Node{root},
+	}
+
+	g.add(root, components)
+	return g
+}
+
+// FromArtifactGraph builds the graph of an artifact dependency graph
+func FromArtifactGraph(output *v1.GetArtifactDependencyGraphOutput) *Graph {
+	root := &Node{}
+	if a := output.Artifact; a != nil {
+		root.Id = ptr.StringValue(a.ComponentId)
+		root.Name = ptr.StringValue(a.Name)
+		root.PackageType = ptr.StringValue(a.PackageType)
+	}
+	if root.Id == "" {
+		root.Id = root.Name
+	}
+
+	return New(root, output.Components)
+}
+
+// FromBuildGraph builds the graph of a build dependency graph
+func FromBuildGraph(output *v1.GetBuildDependencyGraphOutput) *Graph {
+	root := &Node{}
+	if b := output.Build; b != nil {
+		root.Id = ptr.StringValue(b.ComponentId)
+		root.Name = ptr.StringValue(b.Name)
+		root.PackageType = ptr.StringValue(b.PackageType)
+	}
+	if root.Id == "" {
+		root.Id = root.Name
+	}
+
+	return New(root, output.Components)
+}
+
+func (g *Graph) add(parent *Node, components *[]v1.GraphComponent) {
+	if components == nil {
+		return
+	}
+
+	for _, c := range *components {
+		id := ComponentId(c)
+
+		n, ok := g.nodes[id]
+		if !ok {
+			n = &Node{Id: id, Name: ptr.StringValue(c.ComponentName), PackageType: ptr.StringValue(c.PackageType), Version: ptr.StringValue(c.Version)}
+			g.nodes[id] = n
+			g.order = append(g.order, n)
+		}
+
+		if !contains(parent.Dependencies, n) {
+			parent.Dependencies = append(parent.Dependencies, n)
+			n.Dependents = append(n.Dependents, parent)
+		}
+
+		g.add(n, c.Components)
+	}
+}
+
+// ComponentId returns the id of a component, components without id are identified by their name and version
+func ComponentId(c v1.GraphComponent) string {
+	if c.ComponentId != nil && *c.ComponentId != "" {
+		return *c.ComponentId
+	}
+
+	return ptr.StringValue(c.ComponentName) + ":" + ptr.StringValue(c.Version)
+}
+
+// Node returns the node of a component id
+func (g *Graph) Node(id string) (*Node, bool) {
+	n, ok := g.nodes[id]
+	return n, ok
+}
+
+// NodeByImpactPath returns the node at the end of an impact path, e.g. default/npm-local/app/-/app-1.0.0.tgz/lodash:4.17.4
+// The path ends with the component id, usually without its type. Ids can themselves contain slashes, such as
+// @angular/core:7.2.0 or github.com/pkg/errors:v0.8.1, so the longest id the path ends with is chosen, and an id with
+// its type is preferred to the same id without it
+func (g *Graph) NodeByImpactPath(impactPath string) (*Node, bool) {
+	var found *Node
+	longest := -1
+	for _, n := range g.order {
+		for _, id := range []string{n.Id, stripType(n.Id)} {
+			if id == "" || (impactPath != id && !strings.HasSuffix(impactPath, "/"+id)) {
+				continue
+			}
+
+			if len(id) > longest {
+				found, longest = n, len(id)
+			}
+			break
+		}
+	}
+
+	return found, found != nil
+}
+
+// stripType removes the type of a component id, e.g. npm://lodash:4.17.4 becomes lodash:4.17.4
+func stripType(id string) string {
+	if i := strings.Index(id, "://"); i >= 0 {
+		return id[i+3:]
+	}
+
+	return id
+}
+
+// Nodes returns every node once, starting with the root, in order of first appearance in the trees
+func (g *Graph) Nodes() []*Node {
+	return append([]*Node(nil), g.order...)
+}
+
+// Len returns the number of nodes, including the root
+func (g *Graph) Len() int {
+	return len(g.order)
+}
+
+// Walk visits every node once in breadth first order with its depth, the root has depth 0
+// Walk stops when fn returns false
+func (g *Graph) Walk(fn func(n *Node, depth int) bool) {
+	visited := map[*Node]bool{g.Root: true}
+	queue := []*Node{g.Root}
+	depths := map[*Node]int{g.Root: 0}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		if !fn(n, depths[n]) {
+			return
+		}
+
+		for _, d := range n.Dependencies {
+			if !visited[d] {
+				visited[d] = true
+				depths[d] = depths[n] + 1
+				queue = append(queue, d)
+			}
+		}
+	}
+}
+
+// Depth returns the length of the shortest path from the root to the component, 0 for the root and -1 if the component
+// is not part of the graph
+func (g *Graph) Depth(id string) int {
+	depth := -1
+	g.Walk(func(n *Node, d int) bool {
+		if n.Id == id {
+			depth = d
+			return false
+		}
+		return true
+	})
+
+	return depth
+}
+
+// Paths returns every path from the root to the component, each path starts with the root and ends with the component
+func (g *Graph) Paths(id string) [][]*Node {
+	target, ok := g.nodes[id]
+	if !ok {
+		return nil
+	}
+
+	var paths [][]*Node
+	var visit func(n *Node, path []*Node, onPath map[*Node]bool)
+	visit = func(n *Node, path []*Node, onPath map[*Node]bool) {
+		path = append(path, n)
+		if n == target {
+			paths = append(paths, append([]*Node(nil), path...))
+			return
+		}
+
+		onPath[n] = true
+		for _, d := range n.Dependencies {
+			// Dependency cycles are broken rather than followed forever
+			if !onPath[d] {
+				visit(d, path, onPath)
+			}
+		}
+		delete(onPath, n)
+	}
+
+	visit(g.Root, nil, map[*Node]bool{})
+	return paths
+}
+
+// DirectDependenciesOf returns the direct dependencies of the root that pull in the component, in the order they are
+// declared. A direct dependency is returned when it is the component itself
+func (g *Graph) DirectDependenciesOf(id string) []*Node {
+	var direct []*Node
+	for _, d := range g.Root.Dependencies {
+		if d.Id == id || g.reaches(d, id, map[*Node]bool{}) {
+			direct = append(direct, d)
+		}
+	}
+
+	return direct
+}
+
+func (g *Graph) reaches(n *Node, id string, visited map[*Node]bool) bool {
+	if visited[n] {
+		return false
+	}
+	visited[n] = true
+
+	for _, d := range n.Dependencies {
+		if d.Id == id || g.reaches(d, id, visited) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ids returns the ids of the nodes
+func Ids(nodes []*Node) []string {
+	var ids []string
+	for _, n := range nodes {
+		ids = append(ids, n.Id)
+	}
+
+	return ids
+}
+
+func contains(nodes []*Node, n *Node) bool {
+	for _, candidate := range nodes {
+		if candidate == n {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// app depends on express and request, both pull in lodash, express also pulls in debug through body-parser
+const artifactGraphJson = `{
+  "artifact": {"name": "app-1.0.0.tgz", "pkg_type": "npm", "component_id": "npm://app:1.0.0"},
+  "components": [
+    {
+      "component_name": "express", "component_id": "npm://express:4.16.4", "version": "4.16.4",
+      "components": [
+        {
+          "component_name": "body-parser", "component_id": "npm://body-parser:1.18.3", "version": "1.18.3",
+          "components": [{"component_name": "debug", "component_id": "npm://debug:2.6.9", "version": "2.6.9"}]
+        },
+        {"component_name": "lodash", "component_id": "npm://lodash:4.17.4", "version": "4.17.4"}
+      ]
+    },
+    {
+      "component_name": "request", "component_id": "npm://request:2.88.0", "version": "2.88.0",
+      "components": [{"component_name": "lodash", "component_id": "npm://lodash:4.17.4", "version": "4.17.4"}]
+    },
+    {"component_name": "lodash", "component_id": "npm://lodash:4.17.4", "version": "4.17.4"}
+  ]
+}`
+
+func newGraph(t *testing.T) *Graph {
+	var output v1.GetArtifactDependencyGraphOutput
+	if err := json.Unmarshal([]byte(artifactGraphJson), &output); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	return FromArtifactGraph(&output)
+}
+
+func TestNodes(t *testing.T) {
+	g := newGraph(t)
+
+	expected := []string{"npm://app:1.0.0", "npm://express:4.16.4", "npm://body-parser:1.18.3", "npm://debug:2.6.9", "npm://lodash:4.17.4", "npm://request:2.88.0"}
+	if ids := Ids(g.Nodes()); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected nodes %v but got: %v", expected, ids)
+	}
+
+	lodash, ok := g.Node("npm://lodash:4.17.4")
+	if !ok {
+		t.Fatalf("Expected lodash to be part of the graph")
+	}
+
+	expectedDependents := []string{"npm://express:4.16.4", "npm://request:2.88.0", "npm://app:1.0.0"}
+	if ids := Ids(lodash.Dependents); !reflect.DeepEqual(ids, expectedDependents) {
+		t.Errorf("Expected dependents %v but got: %v", expectedDependents, ids)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	g := newGraph(t)
+
+	cases := map[string]int{
+		"npm://app:1.0.0":      0,
+		"npm://express:4.16.4": 1,
+		"npm://lodash:4.17.4":  1,
+		"npm://debug:2.6.9":    3,
+		"npm://unknown:1.0.0":  -1,
+	}
+
+	for id, expected := range cases {
+		if depth := g.Depth(id); depth != expected {
+			t.Errorf("Expected depth %d for %s but got: %d", expected, id, depth)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	g := newGraph(t)
+
+	var paths [][]string
+	for _, p := range g.Paths("npm://lodash:4.17.4") {
+		paths = append(paths, Ids(p))
+	}
+
+	expected := [][]string{
+		{"npm://app:1.0.0", "npm://express:4.16.4", "npm://lodash:4.17.4"},
+		{"npm://app:1.0.0", "npm://request:2.88.0", "npm://lodash:4.17.4"},
+		{"npm://app:1.0.0", "npm://lodash:4.17.4"},
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected paths %v but got: %v", expected, paths)
+	}
+
+	if paths := g.Paths("npm://unknown:1.0.0"); paths != nil {
+		t.Errorf("Expected no paths but got: %v", paths)
+	}
+}
+
+func TestDirectDependenciesOf(t *testing.T) {
+	g := newGraph(t)
+
+	if ids := Ids(g.DirectDependenciesOf("npm://debug:2.6.9")); !reflect.DeepEqual(ids, []string{"npm://express:4.16.4"}) {
+		t.Errorf("Expected debug to be pulled in by express but got: %v", ids)
+	}
+
+	expected := []string{"npm://express:4.16.4", "npm://request:2.88.0", "npm://lodash:4.17.4"}
+	if ids := Ids(g.DirectDependenciesOf("npm://lodash:4.17.4")); !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected lodash to be pulled in by %v but got: %v", expected, ids)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	g := newGraph(t)
+
+	var visited []string
+	g.Walk(func(n *Node, depth int) bool {
+		visited = append(visited, n.Id)
+		return depth < 2
+	})
+
+	// Walk stops at the first node of depth 2
+	expected := []string{"npm://app:1.0.0", "npm://express:4.16.4", "npm://request:2.88.0", "npm://lodash:4.17.4", "npm://body-parser:1.18.3"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Expected visited nodes %v but got: %v", expected, visited)
+	}
+}
+
+func TestComponentId(t *testing.T) {
+	id := ComponentId(v1.GraphComponent{ComponentName: v1.String("lodash"), Version: v1.String("4.17.4")})
+	if id != "lodash:4.17.4" {
+		t.Errorf("Expected lodash:4.17.4 but got: %s", id)
+	}
+}
+
+func TestNodeByImpactPath(t *testing.T) {
+	g := New(&Node{Id: "npm://app:1.0.0"}, &[]v1.GraphComponent{
+		{ComponentId: v1.String("npm://lodash:4.17.4")},
+		{ComponentId: v1.String("npm://core:7.2.0")},
+		{ComponentId: v1.String("npm://@angular/core:7.2.0")},
+		{ComponentId: v1.String("go://github.com/pkg/errors:v0.8.1")},
+		{ComponentId: v1.String("docker://library/nginx:1.15")},
+		{ComponentId: v1.String("gav://org.slf4j:slf4j-api:1.7.25")},
+		{ComponentId: v1.String("npm://errors:v0.8.1")},
+		{ComponentId: v1.String("go://errors:v0.8.1")},
+	})
+
+	cases := []struct {
+		impactPath string
+		expected   string
+	}{
+		{"default/npm-local/app/-/app-1.0.0.tgz/lodash:4.17.4", "npm://lodash:4.17.4"},
+		{"default/npm-local/app/-/app-1.0.0.tgz/@angular/core:7.2.0", "npm://@angular/core:7.2.0"},
+		{"default/npm-local/app/-/app-1.0.0.tgz/core:7.2.0", "npm://core:7.2.0"},
+		{"default/go-local/app/github.com/pkg/errors:v0.8.1", "go://github.com/pkg/errors:v0.8.1"},
+		{"default/docker-local/app/1.0/library/nginx:1.15", "docker://library/nginx:1.15"},
+		{"org.slf4j:slf4j-api:1.7.25", "gav://org.slf4j:slf4j-api:1.7.25"},
+		{"default/go-local/app/go://errors:v0.8.1", "go://errors:v0.8.1"},
+		{"default/npm-local/app/npm://app:1.0.0", "npm://app:1.0.0"},
+		{"default/npm-local/app/-/app-1.0.0.tgz/express:4.16.4", ""},
+		{"default/npm-local/app/-/app-1.0.0.tgz/dash:4.17.4", ""},
+	}
+
+	for _, c := range cases {
+		n, ok := g.NodeByImpactPath(c.impactPath)
+		if c.expected == "" {
+			if ok {
+				t.Errorf("Expected no node for %s but got: %s", c.impactPath, n.Id)
+			}
+			continue
+		}
+
+		if !ok || n.Id != c.expected {
+			t.Errorf("Expected %s for %s but got: %v", c.expected, c.impactPath, n)
+		}
+	}
+}
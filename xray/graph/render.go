@@ -0,0 +1,298 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strings"
+
+	"github.com/xero-oss/go-xray/xray/componentid"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// RenderOptions configures WriteDOT and WriteMermaid
+type RenderOptions struct {
+	// Issues maps component ids to the severity of their most severe issue, these components are highlighted
+	// See Graph.Issues
+	Issues map[string]string
+
+	// MaxDepth collapses the dependencies of the components at this depth into a count, showing the most severe issue
+	// they hide. 0 renders the whole graph
+	MaxDepth int
+
+	// ColorByPackageType fills the components with a color per package type
+	ColorByPackageType bool
+}
+
+// severityColors are the border colors of the highlighted components
+var severityColors = map[string]string{
+	severity.Critical: "#b71c1c",
+	severity.High:     "#e53935",
+	severity.Medium:   "#fb8c00",
+	severity.Low:      "#fdd835",
+}
+
+// packageTypeColors are the fill colors of the common package types, other types get a color of the palette
+var packageTypeColors = map[string]string{
+	"maven":  "#e3f2fd",
+	"npm":    "#fce4ec",
+	"pypi":   "#e8f5e9",
+	"go":     "#e0f7fa",
+	"docker": "#ede7f6",
+	"nuget":  "#f3e5f5",
+	"gem":    "#ffebee",
+	"debian": "#fff3e0",
+	"rpm":    "#fbe9e7",
+	"alpine": "#e8eaf6",
+}
+
+var palette = []string{"#f1f8e9", "#fffde7", "#efebe9", "#eceff1", "#e0f2f1", "#f9fbe7"}
+
+// Issues returns the most severe issue of the components of the graph found at the end of the impact paths of the
+// summary issues, keyed by component id
+func (g *Graph) Issues(summary *v1.Summary) map[string]string {
+	issues := map[string]string{}
+	if summary == nil || summary.Artifacts == nil {
+		return issues
+	}
+
+	for _, artifact := range *summary.Artifacts {
+		if artifact.Issues == nil {
+			continue
+		}
+
+		for _, issue := range *artifact.Issues {
+			if issue.ImpactPath == nil || issue.Severity == nil {
+				continue
+			}
+
+			for _, p := range *issue.ImpactPath {
+				n, ok := g.NodeByImpactPath(p)
+				if !ok {
+					continue
+				}
+
+				if existing, ok := issues[n.Id]; !ok || severity.Compare(*issue.Severity, existing) > 0 {
+					issues[n.Id] = severity.Normalize(*issue.Severity)
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+// rendered is a node as drawn by the renderers
+type rendered struct {
+	node   *Node
+	key    string
+	label  string
+	fill   string
+	border string
+	hidden int
+}
+
+// visible returns the nodes drawn with the options, in order of first appearance, and the edges between them
+func (g *Graph) visible(opts RenderOptions) ([]*rendered, [][2]*rendered) {
+	depths := map[*Node]int{}
+	g.Walk(func(n *Node, depth int) bool {
+		depths[n] = depth
+		return true
+	})
+
+	var nodes []*rendered
+	index := map[*Node]*rendered{}
+	for _, n := range g.order {
+		depth, ok := depths[n]
+		if !ok || (opts.MaxDepth > 0 && depth > opts.MaxDepth) {
+			continue
+		}
+
+		r := &rendered{node: n, key: fmt.Sprintf("n%d", len(nodes)), label: label(n)}
+		if opts.ColorByPackageType {
+			r.fill = packageTypeColor(packageType(n))
+		}
+		if sev, ok := opts.Issues[n.Id]; ok {
+			r.border = severityColors[severity.Normalize(sev)]
+			r.label += "\n" + severity.Normalize(sev)
+		}
+		if opts.MaxDepth > 0 && depth == opts.MaxDepth {
+			hidden := hiddenBelow(n, depths, opts.MaxDepth)
+			r.hidden = len(hidden)
+
+			// The most severe issue of the collapsed components is shown on the component that hides them
+			worst := ""
+			for _, h := range hidden {
+				if sev, ok := opts.Issues[h.Id]; ok && (worst == "" || severity.Compare(sev, worst) > 0) {
+					worst = severity.Normalize(sev)
+				}
+			}
+
+			if r.hidden > 0 && worst != "" {
+				r.label += fmt.Sprintf("\n+%d more (%s)", r.hidden, worst)
+				if r.border == "" {
+					r.border = severityColors[worst]
+				}
+			} else if r.hidden > 0 {
+				r.label += fmt.Sprintf("\n+%d more", r.hidden)
+			}
+		}
+
+		nodes = append(nodes, r)
+		index[n] = r
+	}
+
+	var edges [][2]*rendered
+	for _, from := range nodes {
+		for _, d := range from.node.Dependencies {
+			if to, ok := index[d]; ok && (opts.MaxDepth == 0 || depths[from.node] < opts.MaxDepth) {
+				edges = append(edges, [2]*rendered{from, to})
+			}
+		}
+	}
+
+	return nodes, edges
+}
+
+// hiddenBelow returns the distinct components reachable from n that are deeper than maxDepth
+func hiddenBelow(n *Node, depths map[*Node]int, maxDepth int) []*Node {
+	var hidden []*Node
+	seen := map[*Node]bool{n: true}
+	queue := []*Node{n}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, d := range current.Dependencies {
+			if !seen[d] && depths[d] > maxDepth {
+				seen[d] = true
+				hidden = append(hidden, d)
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	return hidden
+}
+
+// WriteDOT renders the graph in the Graphviz DOT language
+func (g *Graph) WriteDOT(w io.Writer, opts RenderOptions) error {
+	bw := bufio.NewWriter(w)
+	nodes, edges := g.visible(opts)
+
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(g.Root.Name))
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, "  node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\"];")
+
+	for _, r := range nodes {
+		attrs := []string{"label=" + dotQuote(r.label)}
+		if r.fill != "" {
+			attrs = append(attrs, "fillcolor="+dotQuote(r.fill))
+		}
+		if r.border != "" {
+			attrs = append(attrs, "color="+dotQuote(r.border), "penwidth=2")
+		}
+		if r.hidden > 0 {
+			attrs = append(attrs, "style=\"rounded,filled,dashed\"")
+		}
+
+		fmt.Fprintf(bw, "  %s [%s];\n", r.key, strings.Join(attrs, ", "))
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(bw, "  %s -> %s;\n", e[0].key, e[1].key)
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// WriteMermaid renders the graph as a Mermaid flowchart
+func (g *Graph) WriteMermaid(w io.Writer, opts RenderOptions) error {
+	bw := bufio.NewWriter(w)
+	nodes, edges := g.visible(opts)
+
+	fmt.Fprintln(bw, "graph LR")
+	for _, r := range nodes {
+		fmt.Fprintf(bw, "  %s[\"%s\"]\n", r.key, mermaidEscape(r.label))
+	}
+
+	for _, e := range edges {
+		fmt.Fprintf(bw, "  %s --> %s\n", e[0].key, e[1].key)
+	}
+
+	for _, r := range nodes {
+		var styles []string
+		if r.fill != "" {
+			styles = append(styles, "fill:"+r.fill)
+		}
+		if r.border != "" {
+			styles = append(styles, "stroke:"+r.border, "stroke-width:2px")
+		}
+		if r.hidden > 0 {
+			styles = append(styles, "stroke-dasharray:4")
+		}
+
+		if len(styles) > 0 {
+			fmt.Fprintf(bw, "  style %s %s\n", r.key, strings.Join(styles, ","))
+		}
+	}
+
+	return bw.Flush()
+}
+
+func label(n *Node) string {
+	name := n.Name
+	if name == "" {
+		name = n.Id
+	}
+
+	if n.Version != "" && !strings.HasSuffix(name, n.Version) {
+		return name + "\n" + n.Version
+	}
+
+	return name
+}
+
+// packageType returns the package type of a node, derived from its component id when Xray did not report it
+func packageType(n *Node) string {
+	if n.PackageType != "" {
+		return strings.ToLower(n.PackageType)
+	}
+
+	if id, err := componentid.Parse(n.Id); err == nil {
+		switch id.Type {
+		case componentid.TypeMaven:
+			return "maven"
+		case componentid.TypeDebian:
+			return "debian"
+		default:
+			return id.Type
+		}
+	}
+
+	return ""
+}
+
+func packageTypeColor(t string) string {
+	if c, ok := packageTypeColors[t]; ok {
+		return c
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(t))
+	return palette[h.Sum32()%uint32(len(palette))]
+}
+
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
+
+func mermaidEscape(s string) string {
+	s = strings.Replace(s, `"`, "#quot;", -1)
+	return strings.Replace(s, "\n", "<br/>", -1)
+}
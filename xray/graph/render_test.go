@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/xero-oss/go-xray/internal/golden"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+func newSummary() *v1.Summary {
+	return &v1.Summary{
+		Artifacts: &[]v1.SummaryArtifact{
+			{
+				Issues: &[]v1.SummaryArtifactIssue{
+					{
+						Severity:   v1.String("Medium"),
+						ImpactPath: &[]string{"default/npm-local/app/-/app-1.0.0.tgz/express:4.16.4/lodash:4.17.4"},
+					},
+					{
+						Severity:   v1.String("Critical"),
+						ImpactPath: &[]string{"default/npm-local/app/-/app-1.0.0.tgz/lodash:4.17.4"},
+					},
+					{
+						Severity:   v1.String("Low"),
+						ImpactPath: &[]string{"default/npm-local/app/-/app-1.0.0.tgz/express:4.16.4/body-parser:1.18.3/debug:2.6.9"},
+					},
+					{
+						Severity:   v1.String("High"),
+						ImpactPath: &[]string{"default/npm-local/app/-/app-1.0.0.tgz/unknown:1.0.0"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestIssues(t *testing.T) {
+	issues := newGraph(t).Issues(newSummary())
+
+	expected := map[string]string{
+		"npm://lodash:4.17.4": "Critical",
+		"npm://debug:2.6.9":   "Low",
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("Expected issues %v but got: %v", expected, issues)
+	}
+}
+
+func TestWriteDOT_golden(t *testing.T) {
+	g := newGraph(t)
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf, RenderOptions{Issues: g.Issues(newSummary()), ColorByPackageType: true}); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/graph.dot", buf.Bytes())
+}
+
+func TestWriteDOT_maxDepth_golden(t *testing.T) {
+	g := newGraph(t)
+
+	var buf bytes.Buffer
+	if err := g.WriteDOT(&buf, RenderOptions{MaxDepth: 1}); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/graph_depth1.dot", buf.Bytes())
+}
+
+func TestWriteMermaid_golden(t *testing.T) {
+	g := newGraph(t)
+
+	var buf bytes.Buffer
+	if err := g.WriteMermaid(&buf, RenderOptions{Issues: g.Issues(newSummary()), ColorByPackageType: true, MaxDepth: 2}); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/graph.mmd", buf.Bytes())
+}
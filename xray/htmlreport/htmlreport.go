@@ -0,0 +1,233 @@
+// Package htmlreport renders the Xray security and license reports as a self-contained HTML page, without external
+// assets, for sharing or archiving
+package htmlreport
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// DefaultTitle is the title of the page when Data.Title is not set
+const DefaultTitle = "Xray security and license report"
+
+// ReportGetter is implemented by v1.ReportsService
+type ReportGetter interface {
+	GetSecurityReport(ctx context.Context) (*v1.SecurityReport, *http.Response, error)
+	GetTopVulnerabilitiesSecurityReport(ctx context.Context) (*[]v1.TopVulnerabilityReport, *http.Response, error)
+	GetLicenseReport(ctx context.Context) (*v1.LicenseReport, *http.Response, error)
+}
+
+// Data is the content of a report, any of the reports can be nil
+type Data struct {
+	Title     string
+	Generated time.Time
+
+	Security           *v1.SecurityReport
+	TopVulnerabilities []v1.TopVulnerabilityReport
+	License            *v1.LicenseReport
+
+	// Errors are the reasons why some of the reports are missing, they are shown at the top of the page
+	Errors []string
+}
+
+// Fetch gets the last generated reports, usually with client.V1.Reports. The reports are not regenerated, see
+// ReportsService.GenerateSecurityReport and ReportsService.GenerateLicenseReport
+// A report that cannot be fetched is left nil and its error recorded in Data.Errors, an error is only returned when
+// none of the reports could be fetched
+func Fetch(ctx context.Context, reports ReportGetter) (*Data, error) {
+	data := &Data{Generated: time.Now().UTC()}
+
+	security, _, err := reports.GetSecurityReport(ctx)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("security report: %s", err.Error()))
+	} else {
+		data.Security = security
+	}
+
+	top, _, err := reports.GetTopVulnerabilitiesSecurityReport(ctx)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("top vulnerabilities report: %s", err.Error()))
+	} else if top != nil {
+		data.TopVulnerabilities = *top
+	}
+
+	license, _, err := reports.GetLicenseReport(ctx)
+	if err != nil {
+		data.Errors = append(data.Errors, fmt.Sprintf("license report: %s", err.Error()))
+	} else {
+		data.License = license
+	}
+
+	if len(data.Errors) == 3 {
+		return nil, fmt.Errorf("no report could be fetched: %s", strings.Join(data.Errors, ", "))
+	}
+
+	return data, nil
+}
+
+// count is a labelled count with its share of the total, in percent
+type count struct {
+	Label   string
+	Value   int
+	Percent float64
+}
+
+type vulnerability struct {
+	Severity   string
+	Summary    string
+	Cves       []string
+	Components []string
+}
+
+// page is the model of the template
+type page struct {
+	Title     string
+	Generated string
+	Errors    []string
+
+	SecurityUpdated       string
+	RecentVulnerabilities []count
+	RecentComponents      []count
+	TopVulnerabilities    []count
+	TopArtifacts          []v1.SecurityReportTopArtifact
+	Vulnerabilities       []vulnerability
+
+	LicenseUpdated string
+	Compliance     []count
+	Licenses       []count
+}
+
+// Write renders the report as HTML
+func Write(w io.Writer, data *Data) error {
+	return reportTemplate.Execute(w, newPage(data))
+}
+
+func newPage(data *Data) *page {
+	p := &page{Title: data.Title, Errors: data.Errors}
+	if p.Title == "" {
+		p.Title = DefaultTitle
+	}
+	if !data.Generated.IsZero() {
+		p.Generated = data.Generated.Format(time.RFC1123)
+	}
+
+	if s := data.Security; s != nil {
+		p.SecurityUpdated = ptr.StringValue(s.LastUpdate)
+		if s.RecentVulnerabilities != nil {
+			p.RecentVulnerabilities = counts(*s.RecentVulnerabilities)
+			sort.SliceStable(p.RecentVulnerabilities, func(i, j int) bool {
+				return severity.Compare(p.RecentVulnerabilities[i].Label, p.RecentVulnerabilities[j].Label) > 0
+			})
+		}
+		if s.RecentComponents != nil {
+			p.RecentComponents = counts(*s.RecentComponents)
+		}
+		if s.TopVulnerabilities != nil {
+			m := map[string]int{}
+			for _, v := range *s.TopVulnerabilities {
+				m[ptr.StringValue(v.Summary)] += ptr.IntValue(v.TotalAffectedArtifacts)
+			}
+			p.TopVulnerabilities = counts(m)
+		}
+		if s.TopArtifacts != nil {
+			p.TopArtifacts = append(p.TopArtifacts, *s.TopArtifacts...)
+			sort.SliceStable(p.TopArtifacts, func(i, j int) bool {
+				return ptr.IntValue(p.TopArtifacts[i].VulnerabilitiesCount) > ptr.IntValue(p.TopArtifacts[j].VulnerabilitiesCount)
+			})
+		}
+	}
+
+	for _, v := range data.TopVulnerabilities {
+		vuln := vulnerability{Severity: severity.Normalize(ptr.StringValue(v.Severity)), Summary: ptr.StringValue(v.Summary)}
+		if v.Cves != nil {
+			for _, c := range *v.Cves {
+				cve := ptr.StringValue(c.Cve)
+				if c.Cvss != nil && *c.Cvss != "" {
+					cve += " (" + *c.Cvss + ")"
+				}
+				vuln.Cves = append(vuln.Cves, cve)
+			}
+		}
+		if v.AffectedComponents != nil {
+			for _, c := range *v.AffectedComponents {
+				name := ptr.StringValue(c.Name)
+				if name == "" {
+					name = ptr.StringValue(c.Id)
+				}
+				if c.Version != nil && *c.Version != "" {
+					name += ":" + *c.Version
+				}
+				vuln.Components = append(vuln.Components, name)
+			}
+		}
+		p.Vulnerabilities = append(p.Vulnerabilities, vuln)
+	}
+	sort.SliceStable(p.Vulnerabilities, func(i, j int) bool {
+		return severity.Compare(p.Vulnerabilities[i].Severity, p.Vulnerabilities[j].Severity) > 0
+	})
+
+	if l := data.License; l != nil {
+		p.LicenseUpdated = ptr.StringValue(l.LastUpdate)
+		if c := l.Compliance; c != nil {
+			p.Compliance = percentages([]count{
+				{Label: "Valid", Value: ptr.IntValue(c.Valid)},
+				{Label: "Banned", Value: ptr.IntValue(c.Banned)},
+				{Label: "Unknown", Value: ptr.IntValue(c.Unknown)},
+			})
+		}
+		if l.Distribution != nil {
+			p.Licenses = counts(*l.Distribution)
+		}
+	}
+
+	return p
+}
+
+// counts sorts the counts of a map, the largest first, then by label
+func counts(m map[string]int) []count {
+	var list []count
+	for label, value := range m {
+		list = append(list, count{Label: label, Value: value})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Value != list[j].Value {
+			return list[i].Value > list[j].Value
+		}
+		return list[i].Label < list[j].Label
+	})
+
+	return percentages(list)
+}
+
+func percentages(list []count) []count {
+	total := 0
+	for _, c := range list {
+		total += c.Value
+	}
+
+	if total > 0 {
+		for i := range list {
+			list[i].Percent = float64(list[i].Value) * 100 / float64(total)
+		}
+	}
+
+	return list
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"str":   ptr.StringValue,
+	"int":   ptr.IntValue,
+}).Parse(reportHTML))
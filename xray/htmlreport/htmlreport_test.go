@@ -0,0 +1,142 @@
+package htmlreport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xero-oss/go-xray/internal/golden"
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	files := map[string]string{
+		"/api/v1/securityReport":                    "security_report.json",
+		"/api/v1/securityReport/topVulnerabilities": "top_vulnerabilities.json",
+		"/api/v1/licensesReport":                    "license_report.json",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := files[r.URL.Path]
+		if !ok {
+			t.Errorf("Unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("Got the following error: %s", err.Error())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+}
+
+func TestFetch_golden(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	client, err := xray.NewClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	data, err := Fetch(context.Background(), client.V1.Reports)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if data.Generated.IsZero() {
+		t.Errorf("Expected the generation time to be set")
+	}
+	data.Generated = time.Date(2019, 4, 3, 9, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, data); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/report.html", buf.Bytes())
+}
+
+func TestFetch_missingReport(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	client, err := xray.NewClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	data, err := Fetch(context.Background(), failingLicenseReport{client.V1.Reports})
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if data.Security == nil || len(data.TopVulnerabilities) == 0 || data.License != nil {
+		t.Errorf("Expected the security reports only but got: %+v", data)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, data); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	for _, expected := range []string{`<p class="error">Missing license report: report not generated</p>`, "Prototype pollution in lodash", "No licenses"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected %q in the report", expected)
+		}
+	}
+}
+
+// failingLicenseReport fails to get the license report
+type failingLicenseReport struct {
+	ReportGetter
+}
+
+func (failingLicenseReport) GetLicenseReport(ctx context.Context) (*v1.LicenseReport, *http.Response, error) {
+	return nil, nil, fmt.Errorf("report not generated")
+}
+
+func TestWrite_empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, &Data{Title: "Weekly <review>"}); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "<h1>Weekly &lt;review&gt;</h1>") {
+		t.Errorf("Expected the title to be escaped but got:\n%s", out)
+	}
+
+	for _, expected := range []string{"No recent vulnerabilities", "No vulnerable artifacts", "No licenses"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %q in the report", expected)
+		}
+	}
+
+	if strings.Contains(out, "http://") || strings.Contains(out, "https://") {
+		t.Errorf("Expected no external assets in the report")
+	}
+}
+
+func TestCounts(t *testing.T) {
+	c := counts(map[string]int{"b": 1, "a": 1, "c": 2})
+	if len(c) != 3 || c[0].Label != "c" || c[1].Label != "a" || c[2].Label != "b" {
+		t.Fatalf("Expected the counts sorted by value then label but got: %+v", c)
+	}
+
+	if c[0].Percent != 50 {
+		t.Errorf("Expected 50 percent but got: %f", c[0].Percent)
+	}
+}
@@ -0,0 +1,91 @@
+package htmlreport
+
+// reportHTML is the template of the page, the styles are inlined so that the page has no external assets
+const reportHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #212121; margin: 2em auto; max-width: 1100px; padding: 0 1em; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+h2 { font-size: 1.25em; border-bottom: 1px solid #e0e0e0; padding-bottom: 0.3em; margin-top: 2em; }
+h3 { font-size: 1.05em; }
+.meta { color: #757575; font-size: 0.9em; }
+table { border-collapse: collapse; width: 100%; margin: 0.5em 0 1.5em; }
+th, td { text-align: left; padding: 0.4em 0.6em; border-bottom: 1px solid #eeeeee; vertical-align: top; }
+th { background: #fafafa; font-weight: 600; }
+td.num { text-align: right; white-space: nowrap; }
+.bar { background: #eeeeee; height: 0.8em; min-width: 120px; }
+.bar div { background: #1e88e5; height: 100%; }
+.severity { font-weight: 600; }
+.sev-critical { color: #b71c1c; }
+.sev-high { color: #e53935; }
+.sev-medium { color: #fb8c00; }
+.sev-low { color: #9e9d24; }
+.cards { display: flex; gap: 1em; flex-wrap: wrap; }
+.card { border: 1px solid #e0e0e0; border-radius: 4px; padding: 0.8em 1.2em; min-width: 120px; }
+.card .value { font-size: 1.6em; font-weight: 600; }
+.card .label { color: #757575; }
+.empty { color: #757575; font-style: italic; }
+.error { color: #b71c1c; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Generated}}<p class="meta">Generated {{.Generated}}</p>{{end}}
+{{range .Errors}}<p class="error">Missing {{.}}</p>
+{{end}}
+<h2>Security</h2>
+{{if .SecurityUpdated}}<p class="meta">Security report last updated {{.SecurityUpdated}}</p>{{end}}
+
+<h3>Recent vulnerabilities</h3>
+{{if .RecentVulnerabilities}}<div class="cards">
+{{range .RecentVulnerabilities}}<div class="card"><div class="value sev-{{lower .Label}}">{{.Value}}</div><div class="label">{{.Label}}</div></div>
+{{end}}</div>
+{{else}}<p class="empty">No recent vulnerabilities</p>
+{{end}}
+{{if .RecentComponents}}
+<h3>Recently impacted components</h3>
+<table>
+<tr><th>Package type</th><th>Components</th><th></th></tr>
+{{range .RecentComponents}}<tr><td>{{.Label}}</td><td class="num">{{.Value}}</td><td><div class="bar"><div style="width: {{printf "%.1f" .Percent}}%"></div></div></td></tr>
+{{end}}</table>
+{{end}}
+<h3>Top vulnerabilities</h3>
+{{if .Vulnerabilities}}<table>
+<tr><th>Severity</th><th>CVEs</th><th>Summary</th><th>Affected components</th></tr>
+{{range .Vulnerabilities}}<tr><td class="severity sev-{{lower .Severity}}">{{.Severity}}</td><td>{{join .Cves ", "}}</td><td>{{.Summary}}</td><td>{{join .Components ", "}}</td></tr>
+{{end}}</table>
+{{else if .TopVulnerabilities}}<table>
+<tr><th>Summary</th><th>Affected artifacts</th><th></th></tr>
+{{range .TopVulnerabilities}}<tr><td>{{.Label}}</td><td class="num">{{.Value}}</td><td><div class="bar"><div style="width: {{printf "%.1f" .Percent}}%"></div></div></td></tr>
+{{end}}</table>
+{{else}}<p class="empty">No vulnerabilities</p>
+{{end}}
+<h3>Top artifacts</h3>
+{{if .TopArtifacts}}<table>
+<tr><th>Name</th><th>Version</th><th>Package type</th><th>Vulnerabilities</th></tr>
+{{range .TopArtifacts}}<tr><td title="{{str .ComponentId}}">{{str .Name}}</td><td>{{str .Version}}</td><td>{{str .PackageType}}</td><td class="num">{{int .VulnerabilitiesCount}}</td></tr>
+{{end}}</table>
+{{else}}<p class="empty">No vulnerable artifacts</p>
+{{end}}
+<h2>Licenses</h2>
+{{if .LicenseUpdated}}<p class="meta">License report last updated {{.LicenseUpdated}}</p>{{end}}
+
+<h3>Compliance</h3>
+{{if .Compliance}}<div class="cards">
+{{range .Compliance}}<div class="card"><div class="value">{{.Value}}</div><div class="label">{{.Label}} ({{printf "%.1f" .Percent}}%)</div></div>
+{{end}}</div>
+{{else}}<p class="empty">No compliance data</p>
+{{end}}
+<h3>License distribution</h3>
+{{if .Licenses}}<table>
+<tr><th>License</th><th>Components</th><th></th></tr>
+{{range .Licenses}}<tr><td>{{.Label}}</td><td class="num">{{.Value}}</td><td><div class="bar"><div style="width: {{printf "%.1f" .Percent}}%"></div></div></td></tr>
+{{end}}</table>
+{{else}}<p class="empty">No licenses</p>
+{{end}}
+</body>
+</html>
+`
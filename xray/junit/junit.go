@@ -0,0 +1,339 @@
+// Package junit converts Xray build scan and artifact summary results into JUnit XML reports, so that CI systems show
+// them with their test results
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// DefaultName is the name of the reports
+const DefaultName = "Xray"
+
+// noArtifact is the suite of the build scan issues reported without impacted artifacts
+const noArtifact = "(no impacted artifact)"
+
+// Options configures the conversion
+type Options struct {
+	// Name is the name of the report, DefaultName when empty
+	Name string
+
+	// MinimumSeverity is the severity from which issues are failing test cases. Less severe issues are reported as
+	// skipped test cases. Empty fails every issue
+	MinimumSeverity string
+}
+
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type Skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+}
+
+type TestSuite struct {
+	Name       string     `xml:"name,attr"`
+	Tests      int        `xml:"tests,attr"`
+	Failures   int        `xml:"failures,attr"`
+	Skipped    int        `xml:"skipped,attr"`
+	Properties []Property `xml:"properties>property,omitempty"`
+	TestCases  []TestCase `xml:"testcase"`
+}
+
+// TestSuites is a JUnit XML report
+type TestSuites struct {
+	XMLName    xml.Name    `xml:"testsuites"`
+	Name       string      `xml:"name,attr"`
+	Tests      int         `xml:"tests,attr"`
+	Failures   int         `xml:"failures,attr"`
+	Skipped    int         `xml:"skipped,attr"`
+	TestSuites []TestSuite `xml:"testsuite"`
+}
+
+// Write writes the report as indented XML
+func (s *TestSuites) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Failed returns whether the report has failing test cases
+func (s *TestSuites) Failed() bool {
+	return s.Failures > 0
+}
+
+// issue is an issue of an artifact, with the watches that reported it
+type issue struct {
+	severity    string
+	issueType   string
+	id          string
+	summary     string
+	description string
+	watches     []string
+	impactPaths []string
+}
+
+// suite collects the issues of an artifact, an issue reported by several watches is a single test case
+type suite struct {
+	name       string
+	properties []Property
+	index      map[string]int
+	issues     []issue
+}
+
+// builder keeps the suites in order of first appearance
+type builder struct {
+	opts   Options
+	index  map[string]*suite
+	suites []*suite
+}
+
+func newBuilder(opts Options) *builder {
+	return &builder{opts: opts, index: map[string]*suite{}}
+}
+
+func (b *builder) suite(name string, properties ...Property) *suite {
+	s, ok := b.index[name]
+	if !ok {
+		s = &suite{name: name, properties: properties, index: map[string]int{}}
+		b.index[name] = s
+		b.suites = append(b.suites, s)
+	}
+
+	return s
+}
+
+func (s *suite) add(i issue, watch string) {
+	key := i.issueType + "|" + i.id + "|" + i.summary
+	n, ok := s.index[key]
+	if !ok {
+		n = len(s.issues)
+		s.index[key] = n
+		s.issues = append(s.issues, i)
+	} else if severity.Compare(i.severity, s.issues[n].severity) > 0 {
+		s.issues[n].severity = i.severity
+	}
+
+	if watch != "" {
+		s.issues[n].watches = appendUnique(s.issues[n].watches, watch)
+	}
+}
+
+// FromScanBuildOutput converts the alerts of a build scan, one test suite per impacted artifact
+func FromScanBuildOutput(output *v1.ScanBuildOutput, opts Options) *TestSuites {
+	b := newBuilder(opts)
+	if output == nil || output.Alerts == nil {
+		return b.report()
+	}
+
+	for _, alert := range *output.Alerts {
+		if alert.Issues == nil {
+			continue
+		}
+
+		for _, i := range *alert.Issues {
+			value := issue{
+				severity:    severity.Normalize(ptr.StringValue(i.Severity)),
+				issueType:   ptr.StringValue(i.Type),
+				id:          ptr.StringValue(i.CVE),
+				summary:     ptr.StringValue(i.Summary),
+				description: ptr.StringValue(i.Description),
+			}
+
+			if i.ImpactedArtifacts == nil || len(*i.ImpactedArtifacts) == 0 {
+				b.suite(noArtifact).add(value, ptr.StringValue(alert.WatchName))
+				continue
+			}
+
+			for _, a := range *i.ImpactedArtifacts {
+				name := ptr.StringValue(a.DisplayName)
+				if name == "" {
+					name = ptr.StringValue(a.Name)
+				}
+
+				b.suite(name, properties("path", ptr.StringValue(a.Path), "package_type", ptr.StringValue(a.PackageType), "sha256", ptr.StringValue(a.SHA256))...).
+					add(value, ptr.StringValue(alert.WatchName))
+			}
+		}
+	}
+
+	return b.report()
+}
+
+// FromSummary converts the issues of an artifact or build summary, one test suite per artifact. Artifacts without
+// issues are empty test suites
+func FromSummary(summary *v1.Summary, opts Options) *TestSuites {
+	b := newBuilder(opts)
+	if summary == nil || summary.Artifacts == nil {
+		return b.report()
+	}
+
+	for _, artifact := range *summary.Artifacts {
+		var name string
+		var props []Property
+		if g := artifact.General; g != nil {
+			name = ptr.StringValue(g.Name)
+			if name == "" {
+				name = ptr.StringValue(g.Path)
+			}
+			props = properties("component_id", ptr.StringValue(g.ComponentId), "path", ptr.StringValue(g.Path),
+				"package_type", ptr.StringValue(g.PackageType), "sha256", ptr.StringValue(g.Sha256))
+		}
+
+		s := b.suite(name, props...)
+		if artifact.Issues == nil {
+			continue
+		}
+
+		for _, i := range *artifact.Issues {
+			id := ptr.StringValue(i.IssueId)
+			if i.Cves != nil && len(*i.Cves) > 0 && ptr.StringValue((*i.Cves)[0].Cve) != "" {
+				id = ptr.StringValue((*i.Cves)[0].Cve)
+			}
+
+			value := issue{
+				severity:    severity.Normalize(ptr.StringValue(i.Severity)),
+				issueType:   ptr.StringValue(i.IssueType),
+				id:          id,
+				summary:     ptr.StringValue(i.Summary),
+				description: ptr.StringValue(i.Description),
+			}
+			if i.ImpactPath != nil {
+				value.impactPaths = *i.ImpactPath
+			}
+
+			s.add(value, "")
+		}
+	}
+
+	return b.report()
+}
+
+// report builds the test cases, the most severe issues first, and counts them
+func (b *builder) report() *TestSuites {
+	name := b.opts.Name
+	if name == "" {
+		name = DefaultName
+	}
+
+	report := &TestSuites{Name: name}
+	for _, s := range b.suites {
+		suite := TestSuite{Name: s.name, Properties: s.properties}
+
+		issues := append([]issue(nil), s.issues...)
+		sort.SliceStable(issues, func(i, j int) bool {
+			return severity.Compare(issues[i].severity, issues[j].severity) > 0
+		})
+
+		for _, i := range issues {
+			tc := TestCase{Name: testName(i), ClassName: s.name}
+			if b.fails(i.severity) {
+				tc.Failure = &Failure{Message: i.summary, Type: i.severity, Text: details(i)}
+				suite.Failures++
+			} else {
+				tc.Skipped = &Skipped{Message: fmt.Sprintf("%s is below the minimum severity %s", i.severity,
+					severity.Normalize(b.opts.MinimumSeverity))}
+				suite.Skipped++
+			}
+			suite.TestCases = append(suite.TestCases, tc)
+		}
+
+		suite.Tests = len(suite.TestCases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		report.TestSuites = append(report.TestSuites, suite)
+	}
+
+	return report
+}
+
+func (b *builder) fails(sev string) bool {
+	return b.opts.MinimumSeverity == "" || severity.AtLeast(sev, b.opts.MinimumSeverity)
+}
+
+// testName is the id of the issue when known and its summary, e.g. CVE-2018-16487: Prototype pollution in lodash
+// The severity is the type of the failure rather than part of the name, so that a change of severity does not make CI
+// systems report a new test
+func testName(i issue) string {
+	switch {
+	case i.id != "" && i.summary != "":
+		return i.id + ": " + i.summary
+	case i.id != "":
+		return i.id
+	default:
+		return i.summary
+	}
+}
+
+// details is the text of a failure
+func details(i issue) string {
+	var lines []string
+	if i.description != "" {
+		lines = append(lines, i.description, "")
+	}
+	if i.issueType != "" {
+		lines = append(lines, "Type: "+i.issueType)
+	}
+	lines = append(lines, "Severity: "+i.severity)
+	if len(i.watches) > 0 {
+		lines = append(lines, "Watches: "+strings.Join(i.watches, ", "))
+	}
+	for _, p := range i.impactPaths {
+		lines = append(lines, "Impact path: "+p)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// properties builds the properties of the non empty name and value pairs
+func properties(pairs ...string) []Property {
+	var props []Property
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			props = append(props, Property{Name: pairs[i], Value: pairs[i+1]})
+		}
+	}
+
+	return props
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+
+	return append(list, value)
+}
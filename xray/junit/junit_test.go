@@ -0,0 +1,84 @@
+package junit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xero-oss/go-xray/internal/golden"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// checkGolden writes the report and compares it with testdata/name
+func checkGolden(t *testing.T, name string, report *TestSuites) {
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/"+name, buf.Bytes())
+}
+
+func TestFromScanBuildOutput_golden(t *testing.T) {
+	var output v1.ScanBuildOutput
+	golden.ReadJSON(t, "../testdata/build_scan.json", &output)
+
+	checkGolden(t, "build_scan.xml", FromScanBuildOutput(&output, Options{}))
+}
+
+func TestFromSummary_golden(t *testing.T) {
+	var summary v1.Summary
+	golden.ReadJSON(t, "../testdata/artifact_summary.json", &summary)
+
+	checkGolden(t, "artifact_summary.xml", FromSummary(&summary, Options{Name: "struts", MinimumSeverity: severity.High}))
+}
+
+func TestMinimumSeverity(t *testing.T) {
+	var output v1.ScanBuildOutput
+	golden.ReadJSON(t, "../testdata/build_scan.json", &output)
+
+	report := FromScanBuildOutput(&output, Options{MinimumSeverity: severity.High})
+	if report.Tests != 3 {
+		t.Errorf("Expected 3 test cases but got: %d", report.Tests)
+	}
+
+	if report.Failures != 2 || report.Skipped != 1 {
+		t.Errorf("Expected 2 failures and 1 skipped test case but got: %d and %d", report.Failures, report.Skipped)
+	}
+
+	report = FromScanBuildOutput(&output, Options{MinimumSeverity: severity.Critical})
+	if report.Failed() {
+		t.Errorf("Expected no failure but got: %d", report.Failures)
+	}
+}
+
+func TestMergesWatches(t *testing.T) {
+	issue := v1.BuildScanIssue{
+		CVE:               v1.String("CVE-2018-16487"),
+		Severity:          v1.String("High"),
+		Summary:           v1.String("Prototype pollution in lodash"),
+		ImpactedArtifacts: &[]v1.BuildScanArtifact{{DisplayName: v1.String("app:42")}},
+	}
+	output := &v1.ScanBuildOutput{
+		Alerts: &[]v1.BuileScanAlert{
+			{WatchName: v1.String("prod"), Issues: &[]v1.BuildScanIssue{issue}},
+			{WatchName: v1.String("dev"), Issues: &[]v1.BuildScanIssue{issue}},
+		},
+	}
+
+	report := FromScanBuildOutput(output, Options{})
+	if report.Tests != 1 {
+		t.Fatalf("Expected 1 test case but got: %d", report.Tests)
+	}
+
+	if text := report.TestSuites[0].TestCases[0].Failure.Text; text != "Severity: High\nWatches: prod, dev" {
+		t.Errorf("Expected the watches in the failure but got: %s", text)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	report := FromSummary(nil, Options{})
+	if report.Name != DefaultName || report.Tests != 0 || len(report.TestSuites) != 0 {
+		t.Errorf("Expected an empty report but got: %+v", report)
+	}
+}
@@ -0,0 +1,201 @@
+// Package lookup finds the Xray summaries of local files by their checksums, without knowing their Artifactory paths
+package lookup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// DefaultBatchSize is the number of checksums sent per summary request when Lookup.BatchSize is not set
+const DefaultBatchSize = 100
+
+// File is a local file and the checksum Xray indexes it by
+type File struct {
+	Path   string
+	Size   int64
+	Sha256 string
+}
+
+// Result is the summary of a local file. Xray has never indexed the file when Indexed is false
+type Result struct {
+	File    File
+	Indexed bool
+
+	// Artifacts are the artifacts with the checksum of the file, one per repository path it was found at
+	Artifacts []v1.SummaryArtifact
+
+	// Error is the error reported by Xray for the checksum, if any
+	Error string
+}
+
+// SummaryGetter is implemented by v1.SummaryService
+type SummaryGetter interface {
+	GetArtifactSummary(ctx context.Context, getArtifactSummaryInput *v1.GetArtifactSummaryInput) (*v1.Summary, *http.Response, error)
+}
+
+// Lookup queries the artifact summaries of local files
+type Lookup struct {
+	Summary SummaryGetter
+
+	// BatchSize is the number of checksums sent per summary request
+	BatchSize int
+}
+
+// New creates a Lookup, usually with client.V1.Summary
+func New(summary SummaryGetter) *Lookup {
+	return &Lookup{Summary: summary, BatchSize: DefaultBatchSize}
+}
+
+// HashFile computes the SHA-256 checksum of a file
+func HashFile(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return File{}, err
+	}
+
+	return File{
+		Path:   path,
+		Size:   size,
+		Sha256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// HashPaths computes the checksums of files and of the regular files found recursively in directories, sorted by path
+// Hidden directories, such as .git, are skipped
+func HashPaths(paths ...string) ([]File, error) {
+	var files []File
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() {
+				if path != root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			f, err := HashFile(path)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, f)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// Paths hashes the files and directories and looks up their summaries, see HashPaths and Files
+func (l *Lookup) Paths(ctx context.Context, paths ...string) ([]Result, error) {
+	files, err := HashPaths(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Files(ctx, files)
+}
+
+// Files looks up the summaries of the files by SHA-256 checksum, in batches, and returns a result per file in the
+// same order. Files with the same content share their artifacts
+func (l *Lookup) Files(ctx context.Context, files []File) ([]Result, error) {
+	batchSize := l.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	var checksums []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		if !seen[f.Sha256] {
+			seen[f.Sha256] = true
+			checksums = append(checksums, f.Sha256)
+		}
+	}
+
+	artifacts := map[string][]v1.SummaryArtifact{}
+	failures := map[string]string{}
+	for start := 0; start < len(checksums); start += batchSize {
+		end := start + batchSize
+		if end > len(checksums) {
+			end = len(checksums)
+		}
+
+		batch := checksums[start:end]
+		summary, _, err := l.Summary.GetArtifactSummary(ctx, &v1.GetArtifactSummaryInput{Checksums: &batch})
+		if err != nil {
+			return nil, err
+		}
+
+		if summary.Artifacts != nil {
+			for _, a := range *summary.Artifacts {
+				if a.General == nil || a.General.Sha256 == nil {
+					continue
+				}
+
+				checksum := strings.ToLower(*a.General.Sha256)
+				artifacts[checksum] = append(artifacts[checksum], a)
+			}
+		}
+
+		if summary.Errors != nil {
+			for _, e := range *summary.Errors {
+				if e.Identifier != nil && e.Error != nil {
+					failures[strings.ToLower(*e.Identifier)] = *e.Error
+				}
+			}
+		}
+	}
+
+	results := make([]Result, len(files))
+	for i, f := range files {
+		results[i] = Result{
+			File:      f,
+			Artifacts: artifacts[f.Sha256],
+			Indexed:   len(artifacts[f.Sha256]) > 0,
+			Error:     failures[f.Sha256],
+		}
+	}
+
+	return results, nil
+}
+
+// NotIndexed returns the results of the files Xray has never indexed
+func NotIndexed(results []Result) []Result {
+	var missing []Result
+	for _, r := range results {
+		if !r.Indexed {
+			missing = append(missing, r)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,160 @@
+package lookup
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "lookup")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Got the following error: %s", err.Error())
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Got the following error: %s", err.Error())
+		}
+	}
+
+	return dir
+}
+
+func TestHashFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"hello.txt": "hello"})
+	defer os.RemoveAll(dir)
+
+	f, err := HashFile(filepath.Join(dir, "hello.txt"))
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if f.Sha256 != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("Unexpected SHA-256: %s", f.Sha256)
+	}
+	if f.Size != 5 {
+		t.Errorf("Expected a size of 5 but got: %d", f.Size)
+	}
+}
+
+func TestHashPaths(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"lib/a.jar":   "a",
+		"lib/b.jar":   "b",
+		".git/HEAD":   "ref",
+		"app.tgz":     "app",
+		"lib/sub/c.x": "c",
+	})
+	defer os.RemoveAll(dir)
+
+	files, err := HashPaths(dir)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	var paths []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		paths = append(paths, rel)
+	}
+
+	expected := []string{"app.tgz", "lib/a.jar", "lib/b.jar", "lib/sub/c.x"}
+	if len(paths) != len(expected) {
+		t.Fatalf("Expected files %v but got: %v", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Errorf("Expected files %v but got: %v", expected, paths)
+			break
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"indexed.jar": "indexed",
+		"copy.jar":    "indexed",
+		"unknown.jar": "unknown",
+		"other.jar":   "other",
+	})
+	defer os.RemoveAll(dir)
+
+	indexed, _ := HashFile(filepath.Join(dir, "indexed.jar"))
+	unknown, _ := HashFile(filepath.Join(dir, "unknown.jar"))
+
+	var batches [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input v1.GetArtifactSummaryInput
+		json.NewDecoder(r.Body).Decode(&input)
+		batches = append(batches, *input.Checksums)
+
+		summary := v1.Summary{Artifacts: &[]v1.SummaryArtifact{}, Errors: &[]v1.SummaryError{}}
+		for _, checksum := range *input.Checksums {
+			switch checksum {
+			case indexed.Sha256:
+				*summary.Artifacts = append(*summary.Artifacts,
+					v1.SummaryArtifact{General: &v1.SummaryArtifactGeneral{Path: xray.String("default/libs/indexed.jar"), Sha256: xray.String(checksum)}},
+					v1.SummaryArtifact{General: &v1.SummaryArtifactGeneral{Path: xray.String("default/mirror/indexed.jar"), Sha256: xray.String(checksum)}})
+			case unknown.Sha256:
+				*summary.Errors = append(*summary.Errors, v1.SummaryError{Identifier: xray.String(checksum), Error: xray.String("Artifact doesn't exist or not indexed/cached in Xray")})
+			}
+		}
+
+		json.NewEncoder(w).Encode(summary)
+	}))
+	defer server.Close()
+
+	client, err := xray.NewClient(server.URL, nil)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	l := New(client.V1.Summary)
+	l.BatchSize = 2
+
+	results, err := l.Paths(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	// Duplicate files are only queried once
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Errorf("Expected batches of 2 and 1 checksums but got: %v", batches)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results but got: %d", len(results))
+	}
+
+	byName := map[string]Result{}
+	for _, r := range results {
+		byName[filepath.Base(r.File.Path)] = r
+	}
+
+	for _, name := range []string{"indexed.jar", "copy.jar"} {
+		if r := byName[name]; !r.Indexed || len(r.Artifacts) != 2 {
+			t.Errorf("Expected %s to be indexed at 2 paths but got: %+v", name, r)
+		}
+	}
+
+	if r := byName["unknown.jar"]; r.Indexed || r.Error == "" {
+		t.Errorf("Expected unknown.jar not to be indexed with an error but got: %+v", r)
+	}
+
+	if missing := NotIndexed(results); len(missing) != 2 {
+		t.Errorf("Expected 2 files not indexed but got: %+v", missing)
+	}
+}
@@ -0,0 +1,350 @@
+// Package policyeval applies v1 policies locally to artifact summaries, to predict which rules Xray would fire and
+// which actions it would trigger before a policy change is pushed
+package policyeval
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// LicenseSeverity is the severity of license violations when the rule does not set a custom severity
+const LicenseSeverity = severity.High
+
+// unknownLicense is the name Xray reports for components without a recognised license
+const unknownLicense = "unknown"
+
+// Violation is an issue or a license of an artifact matched by a rule
+type Violation struct {
+	Artifact string `json:"artifact"`
+
+	// Type is security or license
+	Type string `json:"type"`
+
+	// Id is the CVE or issue id of a security violation, or the license name of a license violation
+	Id       string `json:"id"`
+	Summary  string `json:"summary,omitempty"`
+	Severity string `json:"severity"`
+
+	// CVSS is the CVSS score of a security violation, if Xray knows it
+	CVSS *float64 `json:"cvss,omitempty"`
+
+	// Components are the components of the artifact with the license, for license violations
+	Components []string `json:"components,omitempty"`
+
+	// Reason explains why the rule matched
+	Reason string `json:"reason"`
+}
+
+// RuleResult is a rule of the policy and the violations it matched
+type RuleResult struct {
+	Rule       string      `json:"rule"`
+	Priority   int         `json:"priority"`
+	Violations []Violation `json:"violations,omitempty"`
+
+	// Actions are the actions of the rule, triggered when it matched violations
+	Actions *v1.PolicyRuleActions `json:"actions,omitempty"`
+}
+
+// Fired reports whether the rule matched violations
+func (r *RuleResult) Fired() bool {
+	return len(r.Violations) > 0
+}
+
+// Result is the outcome of a policy applied to a summary
+type Result struct {
+	Policy string `json:"policy"`
+	Type   string `json:"type"`
+
+	// Rules are all the rules of the policy, by priority
+	Rules []RuleResult `json:"rules"`
+
+	// The actions triggered by the rules that fired
+	FailBuild      bool     `json:"fail_build"`
+	BlockDownload  bool     `json:"block_download"`
+	BlockUnscanned bool     `json:"block_unscanned"`
+	Mails          []string `json:"mails,omitempty"`
+	Webhooks       []string `json:"webhooks,omitempty"`
+}
+
+// Fired returns the rules that matched violations
+func (r *Result) Fired() []RuleResult {
+	var fired []RuleResult
+	for _, rule := range r.Rules {
+		if rule.Fired() {
+			fired = append(fired, rule)
+		}
+	}
+
+	return fired
+}
+
+// Violations returns the violations of all the rules
+func (r *Result) Violations() []Violation {
+	var violations []Violation
+	for _, rule := range r.Rules {
+		violations = append(violations, rule.Violations...)
+	}
+
+	return violations
+}
+
+// Evaluate applies the policy to the issues and licenses of the summary artifacts
+//
+// Rules are applied by priority, the lowest number first, and an issue or a license is only matched by the first rule
+// it satisfies, as Xray does. Security rules match the security issues at least as severe as the minimum severity, or
+// whose CVSS score is in the range. License rules match the banned licenses, or the licenses that are not allowed.
+// Unknown licenses are matched when the rule sets allow_unknown to false
+func Evaluate(policy *v1.Policy, summary *v1.Summary) (*Result, error) {
+	if policy == nil {
+		return nil, fmt.Errorf("policy is required")
+	}
+
+	policyType := ptr.StringValue(policy.Type)
+	if policyType != v1.PolicyTypeSecurity && policyType != v1.PolicyTypeLicense {
+		return nil, fmt.Errorf("unknown policy type %q", policyType)
+	}
+
+	result := &Result{Policy: ptr.StringValue(policy.Name), Type: policyType}
+	if policy.Rules != nil {
+		for _, rule := range *policy.Rules {
+			r := RuleResult{Rule: ptr.StringValue(rule.Name), Actions: rule.Actions}
+			if rule.Priority != nil {
+				r.Priority = *rule.Priority
+			}
+			result.Rules = append(result.Rules, r)
+		}
+	}
+
+	// The rules keep their index in the policy so that the criteria can be found again after sorting
+	order := make([]int, len(result.Rules))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return result.Rules[order[i]].Priority < result.Rules[order[j]].Priority
+	})
+
+	if summary != nil && summary.Artifacts != nil {
+		for _, artifact := range *summary.Artifacts {
+			for _, candidate := range candidates(policyType, artifact) {
+				for _, i := range order {
+					rule := (*policy.Rules)[i]
+					if reason, ok := match(policyType, rule.Criteria, candidate); ok {
+						v := candidate
+						v.Reason = reason
+						if rule.Actions != nil && rule.Actions.CustomSeverity != nil && *rule.Actions.CustomSeverity != "" {
+							v.Severity = severity.Normalize(*rule.Actions.CustomSeverity)
+						}
+						result.Rules[i].Violations = append(result.Rules[i].Violations, v)
+						break
+					}
+				}
+			}
+		}
+	}
+
+	sorted := make([]RuleResult, len(order))
+	for n, i := range order {
+		sorted[n] = result.Rules[i]
+	}
+	result.Rules = sorted
+
+	for _, r := range result.Rules {
+		if r.Fired() && r.Actions != nil {
+			result.trigger(r.Actions)
+		}
+	}
+
+	return result, nil
+}
+
+// trigger records the actions of a rule that fired
+func (r *Result) trigger(a *v1.PolicyRuleActions) {
+	if a.FailBuild != nil && *a.FailBuild {
+		r.FailBuild = true
+	}
+
+	if b := a.BlockDownload; b != nil && b.Active != nil && *b.Active {
+		r.BlockDownload = true
+		if b.Unscanned != nil && *b.Unscanned {
+			r.BlockUnscanned = true
+		}
+	}
+
+	if a.Mails != nil {
+		r.Mails = appendUnique(r.Mails, *a.Mails...)
+	}
+	if a.Webhooks != nil {
+		r.Webhooks = appendUnique(r.Webhooks, *a.Webhooks...)
+	}
+}
+
+// candidates returns the issues or the licenses of an artifact that rules of the policy type can match. The reason
+// is left empty
+func candidates(policyType string, artifact v1.SummaryArtifact) []Violation {
+	var name string
+	if artifact.General != nil {
+		name = ptr.StringValue(artifact.General.Name)
+		if name == "" {
+			name = ptr.StringValue(artifact.General.Path)
+		}
+	}
+
+	var list []Violation
+	switch policyType {
+	case v1.PolicyTypeSecurity:
+		if artifact.Issues == nil {
+			return nil
+		}
+
+		for _, issue := range *artifact.Issues {
+			if t := ptr.StringValue(issue.IssueType); t != "" && t != v1.PolicyTypeSecurity {
+				continue
+			}
+
+			id := ptr.StringValue(issue.IssueId)
+			if issue.Cves != nil && len(*issue.Cves) > 0 && ptr.StringValue((*issue.Cves)[0].Cve) != "" {
+				id = ptr.StringValue((*issue.Cves)[0].Cve)
+			}
+
+			list = append(list, Violation{
+				Artifact: name,
+				Type:     v1.PolicyTypeSecurity,
+				Id:       id,
+				Summary:  ptr.StringValue(issue.Summary),
+				Severity: severity.Normalize(ptr.StringValue(issue.Severity)),
+				CVSS:     cvssScore(issue),
+			})
+		}
+	case v1.PolicyTypeLicense:
+		if artifact.Licenses == nil {
+			return nil
+		}
+
+		for _, license := range *artifact.Licenses {
+			v := Violation{
+				Artifact: name,
+				Type:     v1.PolicyTypeLicense,
+				Id:       ptr.StringValue(license.Name),
+				Summary:  ptr.StringValue(license.FullName),
+				Severity: LicenseSeverity,
+			}
+			if license.Components != nil {
+				v.Components = *license.Components
+			}
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+// match returns why the criteria match the candidate, if they do
+func match(policyType string, c *v1.PolicyRuleCriteria, v Violation) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	if policyType == v1.PolicyTypeSecurity {
+		if c.MinimumSeverity != nil && *c.MinimumSeverity != "" {
+			if severity.AtLeast(v.Severity, *c.MinimumSeverity) {
+				return fmt.Sprintf("severity %s is at least %s", v.Severity, severity.Normalize(*c.MinimumSeverity)), true
+			}
+			return "", false
+		}
+
+		if r := c.CVSSRange; r != nil && v.CVSS != nil {
+			from, to := 0.0, 10.0
+			if r.From != nil {
+				from = float64(*r.From)
+			}
+			if r.To != nil {
+				to = float64(*r.To)
+			}
+
+			if *v.CVSS >= from && *v.CVSS <= to {
+				return fmt.Sprintf("CVSS score %s is between %d and %d", formatScore(*v.CVSS), int(from), int(to)), true
+			}
+		}
+
+		return "", false
+	}
+
+	name := strings.ToLower(strings.TrimSpace(v.Id))
+	if name == "" || name == unknownLicense {
+		if c.AllowUnkown != nil && !*c.AllowUnkown {
+			return "unknown licenses are not allowed", true
+		}
+		return "", false
+	}
+
+	if c.BannedLicenses != nil && contains(*c.BannedLicenses, name) {
+		return fmt.Sprintf("license %s is banned", v.Id), true
+	}
+
+	if c.AllowedLicenses != nil && !contains(*c.AllowedLicenses, name) {
+		return fmt.Sprintf("license %s is not allowed", v.Id), true
+	}
+
+	return "", false
+}
+
+// cvssScore returns the CVSS v3 score of the first CVE of the issue with a score, falling back to the v2 score.
+// Scores are reported as 9.8 or 9.8/CVSS:3.0/AV:N/...
+func cvssScore(issue v1.SummaryArtifactIssue) *float64 {
+	if issue.Cves == nil {
+		return nil
+	}
+
+	for _, cve := range *issue.Cves {
+		for _, s := range []*string{cve.CvssV3, cve.CvssV2} {
+			if s == nil || *s == "" {
+				continue
+			}
+
+			if score, err := strconv.ParseFloat(strings.SplitN(*s, "/", 2)[0], 64); err == nil {
+				return &score
+			}
+		}
+	}
+
+	return nil
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+func contains(licenses []string, name string) bool {
+	for _, l := range licenses {
+		if strings.ToLower(strings.TrimSpace(l)) == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
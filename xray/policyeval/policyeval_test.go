@@ -0,0 +1,197 @@
+package policyeval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+func summary() *v1.Summary {
+	return &v1.Summary{
+		Artifacts: &[]v1.SummaryArtifact{
+			{
+				General: &v1.SummaryArtifactGeneral{Name: v1.String("app-42.tgz")},
+				Issues: &[]v1.SummaryArtifactIssue{
+					{
+						IssueId:   v1.String("XRAY-1"),
+						IssueType: v1.String("security"),
+						Severity:  v1.String("Critical"),
+						Summary:   v1.String("Remote code execution"),
+						Cves:      &[]v1.SummaryArtifactIssueCve{{Cve: v1.String("CVE-2017-5638"), CvssV3: v1.String("10.0/CVSS:3.0/AV:N/AC:L")}},
+					},
+					{
+						IssueId:   v1.String("XRAY-2"),
+						IssueType: v1.String("security"),
+						Severity:  v1.String("Medium"),
+						Summary:   v1.String("Prototype pollution"),
+						Cves:      &[]v1.SummaryArtifactIssueCve{{Cve: v1.String("CVE-2018-16487"), CvssV2: v1.String("6.5")}},
+					},
+					{
+						IssueId:   v1.String("XRAY-3"),
+						IssueType: v1.String("security"),
+						Severity:  v1.String("Low"),
+						Summary:   v1.String("Information disclosure"),
+					},
+					{
+						IssueId:   v1.String("XRAY-4"),
+						IssueType: v1.String("license"),
+						Severity:  v1.String("High"),
+						Summary:   v1.String("GPL-3.0"),
+					},
+				},
+				Licenses: &[]v1.SummaryArtifactLicense{
+					{Name: v1.String("MIT"), Components: &[]string{"npm://lodash:4.17.4"}},
+					{Name: v1.String("GPL-3.0"), FullName: v1.String("GNU General Public License v3.0"), Components: &[]string{"npm://gpl-thing:1.0.0"}},
+					{Name: v1.String("Unknown"), Components: &[]string{"npm://mystery:0.1.0"}},
+				},
+			},
+		},
+	}
+}
+
+func ids(violations []Violation) string {
+	var list []string
+	for _, v := range violations {
+		list = append(list, v.Id)
+	}
+
+	return strings.Join(list, ",")
+}
+
+func TestEvaluate_security(t *testing.T) {
+	policy := &v1.Policy{
+		Name: v1.String("security"),
+		Type: v1.String(v1.PolicyTypeSecurity),
+		Rules: &[]v1.PolicyRule{
+			{
+				Name:     v1.String("cvss"),
+				Priority: xray.Int(2),
+				Criteria: &v1.PolicyRuleCriteria{CVSSRange: &v1.PolicyCVSSRange{From: xray.Int(5), To: xray.Int(10)}},
+				Actions:  &v1.PolicyRuleActions{Mails: &[]string{"security@example.com"}},
+			},
+			{
+				Name:     v1.String("critical"),
+				Priority: xray.Int(1),
+				Criteria: &v1.PolicyRuleCriteria{MinimumSeverity: v1.String("Critical")},
+				Actions: &v1.PolicyRuleActions{
+					FailBuild:     xray.Bool(true),
+					BlockDownload: &v1.BlockDownloadSettings{Active: xray.Bool(true), Unscanned: xray.Bool(false)},
+				},
+			},
+			{
+				Name:     v1.String("low"),
+				Priority: xray.Int(3),
+				Criteria: &v1.PolicyRuleCriteria{MinimumSeverity: v1.String("Minor")},
+				Actions:  &v1.PolicyRuleActions{CustomSeverity: v1.String("Information")},
+			},
+		},
+	}
+
+	result, err := Evaluate(policy, summary())
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if len(result.Rules) != 3 || result.Rules[0].Rule != "critical" || result.Rules[1].Rule != "cvss" {
+		t.Fatalf("Expected the rules sorted by priority but got: %+v", result.Rules)
+	}
+
+	// The critical issue is only matched by the critical rule, the rule with the highest priority
+	if got := ids(result.Rules[0].Violations); got != "CVE-2017-5638" {
+		t.Errorf("Expected the critical rule to match CVE-2017-5638 but got: %s", got)
+	}
+	if got := ids(result.Rules[1].Violations); got != "CVE-2018-16487" {
+		t.Errorf("Expected the cvss rule to match CVE-2018-16487 but got: %s", got)
+	}
+	if got := result.Rules[1].Violations[0].Reason; got != "CVSS score 6.5 is between 5 and 10" {
+		t.Errorf("Unexpected reason: %s", got)
+	}
+
+	// License issues are ignored by security policies
+	if got := ids(result.Rules[2].Violations); got != "XRAY-3" {
+		t.Errorf("Expected the low rule to match XRAY-3 but got: %s", got)
+	}
+	if got := result.Rules[2].Violations[0].Severity; got != "Information" {
+		t.Errorf("Expected the custom severity but got: %s", got)
+	}
+
+	if !result.FailBuild || !result.BlockDownload || result.BlockUnscanned {
+		t.Errorf("Expected fail build and block download but got: %+v", result)
+	}
+	if strings.Join(result.Mails, ",") != "security@example.com" {
+		t.Errorf("Expected the mails of the cvss rule but got: %v", result.Mails)
+	}
+	if len(result.Fired()) != 3 || len(result.Violations()) != 3 {
+		t.Errorf("Expected 3 rules to fire with 3 violations but got: %d and %d", len(result.Fired()), len(result.Violations()))
+	}
+}
+
+func TestEvaluate_license(t *testing.T) {
+	cases := []struct {
+		name     string
+		criteria v1.PolicyRuleCriteria
+		expected string
+	}{
+		{
+			name:     "banned",
+			criteria: v1.PolicyRuleCriteria{BannedLicenses: &[]string{"gpl-3.0"}},
+			expected: "GPL-3.0",
+		},
+		{
+			name:     "allowed",
+			criteria: v1.PolicyRuleCriteria{AllowedLicenses: &[]string{"MIT"}},
+			expected: "GPL-3.0",
+		},
+		{
+			name:     "unknown not allowed",
+			criteria: v1.PolicyRuleCriteria{AllowedLicenses: &[]string{"MIT"}, AllowUnkown: xray.Bool(false)},
+			expected: "GPL-3.0,Unknown",
+		},
+		{
+			name:     "nothing banned",
+			criteria: v1.PolicyRuleCriteria{BannedLicenses: &[]string{"AGPL-3.0"}, AllowUnkown: xray.Bool(true)},
+			expected: "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			criteria := c.criteria
+			policy := &v1.Policy{
+				Name: v1.String("license"),
+				Type: v1.String(v1.PolicyTypeLicense),
+				Rules: &[]v1.PolicyRule{{
+					Name:     v1.String("rule"),
+					Priority: xray.Int(1),
+					Criteria: &criteria,
+					Actions:  &v1.PolicyRuleActions{FailBuild: xray.Bool(true)},
+				}},
+			}
+
+			result, err := Evaluate(policy, summary())
+			if err != nil {
+				t.Fatalf("Got the following error: %s", err.Error())
+			}
+
+			if got := ids(result.Violations()); got != c.expected {
+				t.Errorf("Expected the violations %q but got: %q", c.expected, got)
+			}
+
+			if result.FailBuild != (c.expected != "") {
+				t.Errorf("Expected fail build to be %t", c.expected != "")
+			}
+		})
+	}
+}
+
+func TestEvaluate_invalid(t *testing.T) {
+	if _, err := Evaluate(nil, summary()); err == nil {
+		t.Errorf("Expected an error for a nil policy")
+	}
+
+	if _, err := Evaluate(&v1.Policy{Type: v1.String("operational_risk")}, summary()); err == nil {
+		t.Errorf("Expected an error for an unknown policy type")
+	}
+}
@@ -0,0 +1,371 @@
+// Package policylint checks v1 policies for mistakes before they are sent to Xray, such as criteria that do not match
+// the policy type, inverted CVSS ranges or duplicate rule priorities
+package policylint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// Level is the gravity of a finding
+type Level string
+
+// Levels of the findings. Xray rejects or misapplies policies with errors, warnings are likely mistakes
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+)
+
+// Checks reported in the findings
+const (
+	CheckRequired          = "required"
+	CheckInvalidName       = "invalid-name"
+	CheckUnknownType       = "unknown-type"
+	CheckReadOnly          = "read-only"
+	CheckDuplicateName     = "duplicate-name"
+	CheckDuplicatePriority = "duplicate-priority"
+	CheckInvalidPriority   = "invalid-priority"
+	CheckCriteriaType      = "criteria-type"
+	CheckCriteriaConflict  = "criteria-conflict"
+	CheckUnknownSeverity   = "unknown-severity"
+	CheckLegacySeverity    = "legacy-severity"
+	CheckCVSSRange         = "cvss-range"
+	CheckLicenseConflict   = "license-conflict"
+	CheckDuplicateLicense  = "duplicate-license"
+	CheckEmptyValue        = "empty-value"
+	CheckInvalidMail       = "invalid-mail"
+	CheckBlockDownload     = "block-download"
+	CheckNoActions         = "no-actions"
+)
+
+// Finding is a problem found in a policy. Path locates the field, e.g. rules[1].criteria.cvss_range
+type Finding struct {
+	Level   Level  `json:"level"`
+	Check   string `json:"check"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Level, f.Path, f.Message, f.Check)
+}
+
+// HasErrors reports whether any of the findings is an error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Level == LevelError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Errors returns the findings that are errors
+func Errors(findings []Finding) []Finding {
+	var errors []Finding
+	for _, f := range findings {
+		if f.Level == LevelError {
+			errors = append(errors, f)
+		}
+	}
+
+	return errors
+}
+
+// Err returns the errors of the findings as a single error, each written path: message and separated by "; ", or nil
+// when there is no error. Warnings are ignored
+func Err(findings []Finding) error {
+	errs := Errors(findings)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Path + ": " + e.Message
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// linter collects the findings
+type linter struct {
+	findings []Finding
+}
+
+func (l *linter) add(level Level, check string, path string, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{Level: level, Check: check, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Policy checks a policy and its rules
+func Policy(policy *v1.Policy) []Finding {
+	l := &linter{}
+	l.policy(policy)
+	return l.findings
+}
+
+// Rule checks a rule of a policy of the given type, security or license
+func Rule(policyType string, rule *v1.PolicyRule) []Finding {
+	l := &linter{}
+	l.rule("rule", policyType, rule)
+	return l.findings
+}
+
+// Criteria checks the criteria of a rule of a policy of the given type, security or license
+func Criteria(policyType string, criteria *v1.PolicyRuleCriteria) []Finding {
+	l := &linter{}
+	l.criteria("criteria", policyType, criteria)
+	return l.findings
+}
+
+// Actions checks the actions of a rule
+func Actions(actions *v1.PolicyRuleActions) []Finding {
+	l := &linter{}
+	l.actions("actions", actions)
+	return l.findings
+}
+
+func (l *linter) policy(p *v1.Policy) {
+	if p == nil {
+		l.add(LevelError, CheckRequired, "policy", "the policy is missing")
+		return
+	}
+
+	switch name := ptr.StringValue(p.Name); {
+	case strings.TrimSpace(name) == "":
+		l.add(LevelError, CheckRequired, "name", "the policy name is required")
+	case strings.ContainsAny(name, "/?#%"):
+		l.add(LevelError, CheckInvalidName, "name", "the policy name %q cannot be used in API paths", name)
+	}
+
+	policyType := ptr.StringValue(p.Type)
+	switch policyType {
+	case v1.PolicyTypeSecurity, v1.PolicyTypeLicense:
+	case "":
+		l.add(LevelError, CheckRequired, "type", "the policy type is required")
+	default:
+		l.add(LevelError, CheckUnknownType, "type", "unknown policy type %q, expected %s or %s", policyType,
+			v1.PolicyTypeSecurity, v1.PolicyTypeLicense)
+	}
+
+	if p.Created != nil {
+		l.add(LevelWarning, CheckReadOnly, "created", "created is set by Xray and ignored")
+	}
+	if p.Modified != nil {
+		l.add(LevelWarning, CheckReadOnly, "modified", "modified is set by Xray and ignored")
+	}
+
+	if p.Rules == nil || len(*p.Rules) == 0 {
+		l.add(LevelError, CheckRequired, "rules", "a policy requires at least one rule")
+		return
+	}
+
+	names := map[string]int{}
+	priorities := map[int][]int{}
+	for i := range *p.Rules {
+		rule := &(*p.Rules)[i]
+		path := fmt.Sprintf("rules[%d]", i)
+		l.rule(path, policyType, rule)
+
+		if name := ptr.StringValue(rule.Name); name != "" {
+			if first, ok := names[name]; ok {
+				l.add(LevelError, CheckDuplicateName, path+".name", "rule name %q is already used by rules[%d]", name, first)
+			} else {
+				names[name] = i
+			}
+		}
+
+		if rule.Priority != nil {
+			priorities[*rule.Priority] = append(priorities[*rule.Priority], i)
+		}
+	}
+
+	var duplicates []int
+	for priority, rules := range priorities {
+		if len(rules) > 1 {
+			duplicates = append(duplicates, priority)
+		}
+	}
+	sort.Ints(duplicates)
+
+	for _, priority := range duplicates {
+		rules := priorities[priority]
+		for _, i := range rules[1:] {
+			l.add(LevelError, CheckDuplicatePriority, fmt.Sprintf("rules[%d].priority", i),
+				"priority %d is already used by rules[%d]", priority, rules[0])
+		}
+	}
+}
+
+func (l *linter) rule(path string, policyType string, r *v1.PolicyRule) {
+	if r == nil {
+		l.add(LevelError, CheckRequired, path, "the rule is missing")
+		return
+	}
+
+	if strings.TrimSpace(ptr.StringValue(r.Name)) == "" {
+		l.add(LevelError, CheckRequired, path+".name", "the rule name is required")
+	}
+
+	if r.Priority == nil {
+		l.add(LevelError, CheckRequired, path+".priority", "the rule priority is required")
+	} else if *r.Priority < 1 {
+		l.add(LevelError, CheckInvalidPriority, path+".priority", "priority %d must be 1 or more", *r.Priority)
+	}
+
+	l.criteria(path+".criteria", policyType, r.Criteria)
+
+	if r.Actions == nil {
+		l.add(LevelWarning, CheckNoActions, path+".actions", "the rule has no actions, it only records violations")
+	} else {
+		l.actions(path+".actions", r.Actions)
+	}
+}
+
+func (l *linter) criteria(path string, policyType string, c *v1.PolicyRuleCriteria) {
+	if c == nil {
+		l.add(LevelError, CheckRequired, path, "the rule criteria are required")
+		return
+	}
+
+	security := c.MinimumSeverity != nil || c.CVSSRange != nil
+	license := c.AllowUnkown != nil || c.BannedLicenses != nil || c.AllowedLicenses != nil
+
+	switch policyType {
+	case v1.PolicyTypeSecurity:
+		if license {
+			l.add(LevelError, CheckCriteriaType, path, "a security policy cannot have license criteria")
+		}
+		l.securityCriteria(path, c)
+	case v1.PolicyTypeLicense:
+		if security {
+			l.add(LevelError, CheckCriteriaType, path, "a license policy cannot have security criteria")
+		}
+		l.licenseCriteria(path, c)
+	default:
+		// The type is unknown, check whichever criteria are set
+		if security {
+			l.securityCriteria(path, c)
+		}
+		if license {
+			l.licenseCriteria(path, c)
+		}
+	}
+}
+
+func (l *linter) securityCriteria(path string, c *v1.PolicyRuleCriteria) {
+	switch {
+	case c.MinimumSeverity == nil && c.CVSSRange == nil:
+		l.add(LevelError, CheckRequired, path, "security criteria require min_severity or cvss_range")
+	case c.MinimumSeverity != nil && c.CVSSRange != nil:
+		l.add(LevelError, CheckCriteriaConflict, path, "min_severity and cvss_range cannot be used together")
+	}
+
+	if c.MinimumSeverity != nil {
+		l.severity(path+".min_severity", *c.MinimumSeverity)
+	}
+
+	if r := c.CVSSRange; r != nil {
+		path := path + ".cvss_range"
+		if r.From == nil {
+			l.add(LevelError, CheckRequired, path+".from", "the CVSS range requires from")
+		} else if *r.From < 0 || *r.From > 10 {
+			l.add(LevelError, CheckCVSSRange, path+".from", "from %d is not a CVSS score between 0 and 10", *r.From)
+		}
+
+		if r.To == nil {
+			l.add(LevelError, CheckRequired, path+".to", "the CVSS range requires to")
+		} else if *r.To < 0 || *r.To > 10 {
+			l.add(LevelError, CheckCVSSRange, path+".to", "to %d is not a CVSS score between 0 and 10", *r.To)
+		}
+
+		if r.From != nil && r.To != nil && *r.From > *r.To {
+			l.add(LevelError, CheckCVSSRange, path, "from %d is greater than to %d", *r.From, *r.To)
+		}
+	}
+}
+
+func (l *linter) licenseCriteria(path string, c *v1.PolicyRuleCriteria) {
+	switch {
+	case c.BannedLicenses != nil && c.AllowedLicenses != nil:
+		l.add(LevelError, CheckLicenseConflict, path, "banned_licenses and allowed_licenses cannot be used together")
+	case c.BannedLicenses == nil && c.AllowedLicenses == nil && c.AllowUnkown == nil:
+		l.add(LevelError, CheckRequired, path, "license criteria require banned_licenses, allowed_licenses or allow_unknown")
+	}
+
+	l.licenses(path+".banned_licenses", c.BannedLicenses)
+	l.licenses(path+".allowed_licenses", c.AllowedLicenses)
+}
+
+func (l *linter) licenses(path string, licenses *[]string) {
+	if licenses == nil {
+		return
+	}
+
+	if len(*licenses) == 0 {
+		l.add(LevelError, CheckEmptyValue, path, "the license list is empty")
+		return
+	}
+
+	seen := map[string]bool{}
+	for i, license := range *licenses {
+		key := strings.ToLower(strings.TrimSpace(license))
+		switch {
+		case key == "":
+			l.add(LevelError, CheckEmptyValue, fmt.Sprintf("%s[%d]", path, i), "the license name is empty")
+		case seen[key]:
+			l.add(LevelWarning, CheckDuplicateLicense, fmt.Sprintf("%s[%d]", path, i), "license %q is listed more than once", license)
+		}
+		seen[key] = true
+	}
+}
+
+func (l *linter) severity(path string, s string) {
+	if !severity.Valid(s) {
+		l.add(LevelError, CheckUnknownSeverity, path, "unknown severity %q, expected one of %s", s, strings.Join(severity.All, ", "))
+		return
+	}
+
+	if normalized := severity.Normalize(s); normalized != s {
+		l.add(LevelWarning, CheckLegacySeverity, path, "severity %q is read as %s", s, normalized)
+	}
+}
+
+func (l *linter) actions(path string, a *v1.PolicyRuleActions) {
+	if a == nil {
+		return
+	}
+
+	if a.CustomSeverity != nil {
+		l.severity(path+".custom_severity", *a.CustomSeverity)
+	}
+
+	if a.Mails != nil {
+		for i, mail := range *a.Mails {
+			at := strings.Index(mail, "@")
+			if at <= 0 || at == len(mail)-1 || strings.ContainsAny(mail, " ,;") {
+				l.add(LevelError, CheckInvalidMail, fmt.Sprintf("%s.mails[%d]", path, i), "%q is not a mail address", mail)
+			}
+		}
+	}
+
+	if a.Webhooks != nil {
+		for i, webhook := range *a.Webhooks {
+			if strings.TrimSpace(webhook) == "" {
+				l.add(LevelError, CheckEmptyValue, fmt.Sprintf("%s.webhooks[%d]", path, i), "the webhook name is empty")
+			}
+		}
+	}
+
+	if b := a.BlockDownload; b != nil && b.Unscanned != nil && *b.Unscanned && (b.Active == nil || !*b.Active) {
+		l.add(LevelWarning, CheckBlockDownload, path+".block_download",
+			"unscanned artifacts are only blocked when active is true")
+	}
+}
@@ -0,0 +1,219 @@
+package policylint
+
+import (
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+func securityPolicy() *v1.Policy {
+	return &v1.Policy{
+		Name: v1.String("high-severity"),
+		Type: v1.String(v1.PolicyTypeSecurity),
+		Rules: &[]v1.PolicyRule{
+			{
+				Name:     v1.String("high"),
+				Priority: xray.Int(1),
+				Criteria: &v1.PolicyRuleCriteria{MinimumSeverity: v1.String("High")},
+				Actions:  &v1.PolicyRuleActions{FailBuild: xray.Bool(true), Mails: &[]string{"security@example.com"}},
+			},
+			{
+				Name:     v1.String("cvss"),
+				Priority: xray.Int(2),
+				Criteria: &v1.PolicyRuleCriteria{CVSSRange: &v1.PolicyCVSSRange{From: xray.Int(7), To: xray.Int(10)}},
+				Actions:  &v1.PolicyRuleActions{BlockDownload: &v1.BlockDownloadSettings{Active: xray.Bool(true), Unscanned: xray.Bool(true)}},
+			},
+		},
+	}
+}
+
+// checks returns the check and path of the findings
+func checks(findings []Finding) []string {
+	var list []string
+	for _, f := range findings {
+		list = append(list, f.Check+" "+f.Path)
+	}
+
+	return list
+}
+
+func expectChecks(t *testing.T, findings []Finding, expected ...string) {
+	got := checks(findings)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected the findings %v but got: %v", expected, got)
+	}
+
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected the findings %v but got: %v", expected, got)
+			return
+		}
+	}
+}
+
+func TestPolicy_valid(t *testing.T) {
+	findings := Policy(securityPolicy())
+	if len(findings) != 0 {
+		t.Errorf("Expected no finding but got: %v", findings)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(p *v1.Policy)
+		expected []string
+	}{
+		{
+			name:     "missing name and type",
+			modify:   func(p *v1.Policy) { p.Name, p.Type = nil, nil },
+			expected: []string{"required name", "required type"},
+		},
+		{
+			name:     "unknown type",
+			modify:   func(p *v1.Policy) { p.Type = v1.String("operational_risk") },
+			expected: []string{"unknown-type type"},
+		},
+		{
+			name:     "name with slash",
+			modify:   func(p *v1.Policy) { p.Name = v1.String("team/high") },
+			expected: []string{"invalid-name name"},
+		},
+		{
+			name:     "no rules",
+			modify:   func(p *v1.Policy) { p.Rules = &[]v1.PolicyRule{} },
+			expected: []string{"required rules"},
+		},
+		{
+			name: "duplicate priority and name",
+			modify: func(p *v1.Policy) {
+				(*p.Rules)[1].Priority = xray.Int(1)
+				(*p.Rules)[1].Name = v1.String("high")
+			},
+			expected: []string{"duplicate-name rules[1].name", "duplicate-priority rules[1].priority"},
+		},
+		{
+			name: "license criteria in a security policy",
+			modify: func(p *v1.Policy) {
+				(*p.Rules)[0].Criteria.BannedLicenses = &[]string{"GPL-3.0"}
+			},
+			expected: []string{"criteria-type rules[0].criteria"},
+		},
+		{
+			name: "inverted CVSS range",
+			modify: func(p *v1.Policy) {
+				(*p.Rules)[1].Criteria.CVSSRange.From = xray.Int(9)
+				(*p.Rules)[1].Criteria.CVSSRange.To = xray.Int(5)
+			},
+			expected: []string{"cvss-range rules[1].criteria.cvss_range"},
+		},
+		{
+			name: "CVSS range out of bounds",
+			modify: func(p *v1.Policy) {
+				(*p.Rules)[1].Criteria.CVSSRange.To = xray.Int(11)
+			},
+			expected: []string{"cvss-range rules[1].criteria.cvss_range.to"},
+		},
+		{
+			name: "severity and CVSS range together",
+			modify: func(p *v1.Policy) {
+				(*p.Rules)[0].Criteria.CVSSRange = &v1.PolicyCVSSRange{From: xray.Int(1), To: xray.Int(2)}
+			},
+			expected: []string{"criteria-conflict rules[0].criteria"},
+		},
+		{
+			name: "unknown and legacy severities",
+			modify: func(p *v1.Policy) {
+				(*p.Rules)[0].Criteria.MinimumSeverity = v1.String("Severe")
+				(*p.Rules)[0].Actions.CustomSeverity = v1.String("Major")
+			},
+			expected: []string{"unknown-severity rules[0].criteria.min_severity", "legacy-severity rules[0].actions.custom_severity"},
+		},
+		{
+			name: "invalid priority and actions",
+			modify: func(p *v1.Policy) {
+				(*p.Rules)[0].Priority = xray.Int(0)
+				(*p.Rules)[0].Actions.Mails = &[]string{"security"}
+				(*p.Rules)[1].Actions.BlockDownload.Active = nil
+			},
+			expected: []string{"invalid-priority rules[0].priority", "invalid-mail rules[0].actions.mails[0]", "block-download rules[1].actions.block_download"},
+		},
+		{
+			name: "read-only fields and missing actions",
+			modify: func(p *v1.Policy) {
+				p.Created = v1.String("2019-04-01T10:00:00.000Z")
+				(*p.Rules)[1].Actions = nil
+			},
+			expected: []string{"read-only created", "no-actions rules[1].actions"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := securityPolicy()
+			c.modify(p)
+			expectChecks(t, Policy(p), c.expected...)
+		})
+	}
+}
+
+func TestCriteria_license(t *testing.T) {
+	expectChecks(t, Criteria(v1.PolicyTypeLicense, &v1.PolicyRuleCriteria{
+		BannedLicenses:  &[]string{"GPL-3.0", "gpl-3.0"},
+		AllowedLicenses: &[]string{"MIT"},
+	}), "license-conflict criteria", "duplicate-license criteria.banned_licenses[1]")
+
+	expectChecks(t, Criteria(v1.PolicyTypeLicense, &v1.PolicyRuleCriteria{
+		MinimumSeverity: v1.String("High"),
+		BannedLicenses:  &[]string{},
+	}), "criteria-type criteria", "empty-value criteria.banned_licenses")
+
+	expectChecks(t, Criteria(v1.PolicyTypeLicense, &v1.PolicyRuleCriteria{}), "required criteria")
+
+	expectChecks(t, Criteria(v1.PolicyTypeLicense, &v1.PolicyRuleCriteria{
+		AllowUnkown:     xray.Bool(false),
+		AllowedLicenses: &[]string{"MIT", "Apache-2.0"},
+	}))
+}
+
+func TestActions_nil(t *testing.T) {
+	if findings := Actions(nil); len(findings) != 0 {
+		t.Errorf("Expected no finding but got: %v", findings)
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	p := securityPolicy()
+	(*p.Rules)[1].Actions.BlockDownload.Active = nil
+
+	findings := Policy(p)
+	if len(findings) != 1 || HasErrors(findings) {
+		t.Errorf("Expected a single warning but got: %v", findings)
+	}
+
+	p.Type = nil
+	findings = Policy(p)
+	if !HasErrors(findings) || len(Errors(findings)) != 1 {
+		t.Errorf("Expected a single error but got: %v", findings)
+	}
+
+	if s := Errors(findings)[0].String(); s != "error: type: the policy type is required (required)" {
+		t.Errorf("Unexpected string: %s", s)
+	}
+}
+
+func TestErr(t *testing.T) {
+	p := securityPolicy()
+	(*p.Rules)[1].Actions.BlockDownload.Active = nil
+	if err := Err(Policy(p)); err != nil {
+		t.Errorf("Expected warnings to be ignored but got: %s", err.Error())
+	}
+
+	p.Type = nil
+	p.Name = nil
+	err := Err(Policy(p))
+	if err == nil || err.Error() != "name: the policy name is required; type: the policy type is required" {
+		t.Errorf("Expected the errors to be joined but got: %v", err)
+	}
+}
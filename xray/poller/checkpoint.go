@@ -0,0 +1,111 @@
+package poller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Checkpoint records how far a ViolationPoller has progressed. CreatedFrom is the creation time of the newest violation
+// delivered and Seen holds the keys of the violations already delivered at or after that time, since the next query
+// includes them again
+type Checkpoint struct {
+	CreatedFrom string            `json:"created_from,omitempty"`
+	Seen        map[string]string `json:"seen,omitempty"`
+}
+
+// CheckpointStore persists checkpoints between runs of a ViolationPoller
+type CheckpointStore interface {
+	// Load returns the last saved checkpoint, or nil if there is none
+	Load() (*Checkpoint, error)
+
+	// Save replaces the saved checkpoint
+	Save(checkpoint *Checkpoint) error
+}
+
+// FileCheckpointStore saves the checkpoint as JSON in a file
+type FileCheckpointStore struct {
+	Path string
+}
+
+// Load reads the checkpoint file, a missing file is treated as no checkpoint
+func (s *FileCheckpointStore) Load() (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := new(Checkpoint)
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+// Save writes the checkpoint to a temporary file and renames it over the checkpoint file
+func (s *FileCheckpointStore) Save(checkpoint *Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// MemoryCheckpointStore keeps the checkpoint in memory, it does not survive restarts
+type MemoryCheckpointStore struct {
+	mu         sync.Mutex
+	checkpoint *Checkpoint
+}
+
+// Load returns a copy of the saved checkpoint
+func (s *MemoryCheckpointStore) Load() (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.checkpoint.copy(), nil
+}
+
+// Save stores a copy of the checkpoint
+func (s *MemoryCheckpointStore) Save(checkpoint *Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoint = checkpoint.copy()
+	return nil
+}
+
+func (c *Checkpoint) copy() *Checkpoint {
+	if c == nil {
+		return nil
+	}
+
+	seen := make(map[string]string, len(c.Seen))
+	for k, v := range c.Seen {
+		seen[k] = v
+	}
+
+	return &Checkpoint{CreatedFrom: c.CreatedFrom, Seen: seen}
+}
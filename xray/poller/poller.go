@@ -0,0 +1,238 @@
+// Package poller streams new Xray violations by repeatedly querying the violations API
+package poller
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xero-oss/go-xray/internal/paging"
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+const (
+	// DefaultInterval is the time between two polls when ViolationPoller.Interval is not set
+	DefaultInterval = time.Minute
+
+	// DefaultPageSize is the number of violations requested per page when ViolationPoller.PageSize is not set
+	DefaultPageSize = paging.DefaultPageSize
+)
+
+// ViolationsGetter is implemented by v1.ViolationsService
+type ViolationsGetter interface {
+	GetViolations(ctx context.Context, getViolationsInput *v1.GetViolationsInput) (*v1.GetViolationsOutput, *http.Response, error)
+}
+
+// ViolationPoller queries the violations created since its checkpoint and delivers the ones it has not delivered before
+type ViolationPoller struct {
+	// Violations is the service queried, usually client.V1.Violations
+	Violations ViolationsGetter
+
+	// Store persists the checkpoint so that restarts do not deliver old violations again
+	Store CheckpointStore
+
+	// Filters restricts the violations queried, CreatedFrom is managed by the poller
+	Filters *v1.GetViolationsFilters
+
+	// Start is the creation time violations are queried from when there is no checkpoint, zero means now
+	Start time.Time
+
+	Interval time.Duration
+	PageSize int
+}
+
+// NewViolationPoller creates a ViolationPoller with the default interval and page size
+func NewViolationPoller(violations ViolationsGetter, store CheckpointStore) *ViolationPoller {
+	return &ViolationPoller{
+		Violations: violations,
+		Store:      store,
+		Interval:   DefaultInterval,
+		PageSize:   DefaultPageSize,
+	}
+}
+
+// Run polls until the context is done, sending every new violation to out in creation order
+// It returns the context error, or the first error returned by the API or the checkpoint store
+func (p *ViolationPoller) Run(ctx context.Context, out chan<- v1.Violation) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		violations, checkpoint, err := p.poll(ctx)
+		if err != nil {
+			return err
+		}
+
+		// The checkpoint only records delivered violations, the others are queried again after a restart
+		for _, violation := range violations {
+			select {
+			case out <- violation:
+				record(checkpoint, violation)
+			case <-ctx.Done():
+				if err := p.save(checkpoint); err != nil {
+					return err
+				}
+				return ctx.Err()
+			}
+		}
+
+		if err := p.save(checkpoint); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll queries the violations once and returns the ones not delivered before, in creation order
+// The checkpoint is saved before returning, so the violations are considered delivered
+func (p *ViolationPoller) Poll(ctx context.Context) ([]v1.Violation, error) {
+	violations, checkpoint, err := p.poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, violation := range violations {
+		record(checkpoint, violation)
+	}
+
+	if err := p.save(checkpoint); err != nil {
+		return nil, err
+	}
+
+	return violations, nil
+}
+
+// poll returns the new violations in creation order together with the loaded checkpoint, which is left unchanged
+func (p *ViolationPoller) poll(ctx context.Context) ([]v1.Violation, *Checkpoint, error) {
+	checkpoint, err := p.Store.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if checkpoint == nil {
+		start := p.Start
+		if start.IsZero() {
+			start = time.Now()
+		}
+		checkpoint = &Checkpoint{CreatedFrom: start.UTC().Format(time.RFC3339)}
+	}
+
+	if checkpoint.Seen == nil {
+		checkpoint.Seen = map[string]string{}
+	}
+
+	all, err := p.fetch(ctx, checkpoint.CreatedFrom)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fresh []v1.Violation
+	keys := map[string]bool{}
+	for _, violation := range all {
+		key := Key(violation)
+		if _, ok := checkpoint.Seen[key]; ok || keys[key] {
+			continue
+		}
+
+		keys[key] = true
+		fresh = append(fresh, violation)
+	}
+
+	sort.SliceStable(fresh, func(i, j int) bool {
+		return createdTime(fresh[i]).Before(createdTime(fresh[j]))
+	})
+
+	return fresh, checkpoint, nil
+}
+
+func (p *ViolationPoller) save(checkpoint *Checkpoint) error {
+	advance(checkpoint)
+	return p.Store.Save(checkpoint)
+}
+
+// fetch returns every violation created from createdFrom, page by page
+func (p *ViolationPoller) fetch(ctx context.Context, createdFrom string) ([]v1.Violation, error) {
+	filters := v1.GetViolationsFilters{}
+	if p.Filters != nil {
+		filters = *p.Filters
+	}
+	filters.CreatedFrom = &createdFrom
+
+	var all []v1.Violation
+	it := paging.NewViolationIterator(p.Violations, &filters, p.PageSize)
+	for it.Next(ctx) {
+		all = append(all, it.Violation())
+	}
+
+	return all, it.Err()
+}
+
+// record marks a violation as delivered. A violation whose creation time does not parse is recorded with the start
+// of the query that returned it, so that it is forgotten once the checkpoint moves past that query
+func record(checkpoint *Checkpoint, violation v1.Violation) {
+	created := ptr.StringValue(violation.Created)
+	if _, err := time.Parse(time.RFC3339, created); err != nil {
+		created = checkpoint.CreatedFrom
+	}
+
+	checkpoint.Seen[Key(violation)] = created
+}
+
+// advance moves CreatedFrom to the newest violation seen and forgets the keys of older violations, which the next
+// query no longer returns. A CreatedFrom that does not parse is replaced by the newest violation seen, and the keys
+// whose time does not parse are forgotten since they could never be
+func advance(checkpoint *Checkpoint) {
+	newest, _ := time.Parse(time.RFC3339, checkpoint.CreatedFrom)
+
+	for _, created := range checkpoint.Seen {
+		if t, err := time.Parse(time.RFC3339, created); err == nil && t.After(newest) {
+			newest = t
+		}
+	}
+
+	// CreatedFrom only has a precision of a second, keep everything the next query can return again
+	newest = newest.Truncate(time.Second)
+
+	for key, created := range checkpoint.Seen {
+		if t, err := time.Parse(time.RFC3339, created); err != nil || t.Before(newest) {
+			delete(checkpoint.Seen, key)
+		}
+	}
+
+	if !newest.IsZero() {
+		checkpoint.CreatedFrom = newest.UTC().Format(time.RFC3339)
+	}
+}
+
+// Key identifies a violation by its issue, its watch and the artifacts it impacts
+func Key(violation v1.Violation) string {
+	var artifacts []string
+	if violation.ImpactedArtifacts != nil {
+		artifacts = append(artifacts, *violation.ImpactedArtifacts...)
+		sort.Strings(artifacts)
+	}
+
+	return strings.Join([]string{
+		ptr.StringValue(violation.IssueId),
+		ptr.StringValue(violation.WatchName),
+		strings.Join(artifacts, ","),
+	}, "|")
+}
+
+func createdTime(violation v1.Violation) time.Time {
+	t, _ := time.Parse(time.RFC3339, ptr.StringValue(violation.Created))
+	return t
+}
@@ -0,0 +1,174 @@
+package poller
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+type fakeViolations struct {
+	violations []v1.Violation
+	queries    []string
+}
+
+func (f *fakeViolations) GetViolations(ctx context.Context, input *v1.GetViolationsInput) (*v1.GetViolationsOutput, *http.Response, error) {
+	f.queries = append(f.queries, *input.Filters.CreatedFrom)
+
+	from, _ := time.Parse(time.RFC3339, *input.Filters.CreatedFrom)
+
+	var matching []v1.Violation
+	for _, v := range f.violations {
+		if !createdTime(v).Before(from) {
+			matching = append(matching, v)
+		}
+	}
+
+	page, _ := strconv.Atoi(*input.Pagination.Offset)
+	start := (page - 1) * *input.Pagination.Limit
+	end := start + *input.Pagination.Limit
+	if start > len(matching) {
+		start = len(matching)
+	}
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	violations := matching[start:end]
+	total := len(matching)
+	return &v1.GetViolationsOutput{TotalViolations: &total, Violations: &violations}, nil, nil
+}
+
+func newViolation(issue string, created string, artifacts ...string) v1.Violation {
+	watch := "prod"
+	return v1.Violation{
+		IssueId:           &issue,
+		WatchName:         &watch,
+		Created:           &created,
+		ImpactedArtifacts: &artifacts,
+	}
+}
+
+func TestViolationPollerPoll_deduplicates(t *testing.T) {
+	fake := &fakeViolations{
+		violations: []v1.Violation{
+			newViolation("XRAY-2", "2019-04-02T11:00:01Z", "a.jar"),
+			newViolation("XRAY-1", "2019-04-02T11:00:00Z", "a.jar"),
+			newViolation("XRAY-1", "2019-04-02T11:00:01Z", "b.jar"),
+		},
+	}
+
+	p := NewViolationPoller(fake, &MemoryCheckpointStore{})
+	p.Start = time.Date(2019, 4, 2, 0, 0, 0, 0, time.UTC)
+	p.PageSize = 2
+
+	first, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if len(first) != 3 {
+		t.Fatalf("Expected 3 violations but got: %d", len(first))
+	}
+
+	if *first[0].IssueId != "XRAY-1" {
+		t.Errorf("Expected the oldest violation first but got: %s", *first[0].IssueId)
+	}
+
+	fake.violations = append(fake.violations, newViolation("XRAY-3", "2019-04-02T11:00:01Z", "a.jar"))
+
+	second, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if len(second) != 1 || *second[0].IssueId != "XRAY-3" {
+		t.Errorf("Expected only XRAY-3 but got: %v", second)
+	}
+
+	if last := fake.queries[len(fake.queries)-1]; last != "2019-04-02T11:00:01Z" {
+		t.Errorf("Expected to query from the newest violation but got: %s", last)
+	}
+}
+
+func TestViolationPollerRun_resumesFromCheckpointFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "poller")
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	fake := &fakeViolations{
+		violations: []v1.Violation{
+			newViolation("XRAY-1", "2019-04-02T11:00:00Z", "a.jar"),
+		},
+	}
+	store := &FileCheckpointStore{Path: filepath.Join(dir, "checkpoint.json")}
+
+	run := func() []v1.Violation {
+		p := NewViolationPoller(fake, store)
+		p.Start = time.Date(2019, 4, 2, 0, 0, 0, 0, time.UTC)
+		p.Interval = time.Hour
+
+		ctx, cancel := context.WithCancel(context.Background())
+		out := make(chan v1.Violation)
+		done := make(chan error)
+		go func() { done <- p.Run(ctx, out) }()
+
+		var received []v1.Violation
+		for {
+			select {
+			case v := <-out:
+				received = append(received, v)
+			case <-time.After(50 * time.Millisecond):
+				cancel()
+				<-done
+				return received
+			}
+		}
+	}
+
+	if got := run(); len(got) != 1 {
+		t.Errorf("Expected 1 violation on the first run but got: %d", len(got))
+	}
+
+	if got := run(); len(got) != 0 {
+		t.Errorf("Expected no violation after restarting but got: %d", len(got))
+	}
+}
+
+func TestAdvance_prunesUnparsableTimes(t *testing.T) {
+	checkpoint := &Checkpoint{
+		CreatedFrom: "2019-04-02T11:00:00Z",
+		Seen:        map[string]string{},
+	}
+
+	record(checkpoint, newViolation("XRAY-1", "02/04/2019 11:00", "a.jar"))
+	if created := checkpoint.Seen[Key(newViolation("XRAY-1", "", "a.jar"))]; created != "2019-04-02T11:00:00Z" {
+		t.Errorf("Expected an unparsable time to be recorded as the query start but got: %s", created)
+	}
+
+	checkpoint.Seen["XRAY-2|prod|b.jar"] = "yesterday"
+	record(checkpoint, newViolation("XRAY-3", "2019-04-02T12:00:00Z", "a.jar"))
+	advance(checkpoint)
+
+	if checkpoint.CreatedFrom != "2019-04-02T12:00:00Z" {
+		t.Errorf("Expected to advance to the newest violation but got: %s", checkpoint.CreatedFrom)
+	}
+	if len(checkpoint.Seen) != 1 {
+		t.Errorf("Expected only XRAY-3 to be kept but got: %v", checkpoint.Seen)
+	}
+
+	checkpoint = &Checkpoint{CreatedFrom: "not a time", Seen: map[string]string{"XRAY-1|prod|a.jar": "not a time"}}
+	advance(checkpoint)
+
+	if len(checkpoint.Seen) != 0 || checkpoint.CreatedFrom != "not a time" {
+		t.Errorf("Expected the unparsable keys to be forgotten but got: %+v", checkpoint)
+	}
+}
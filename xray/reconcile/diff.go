@@ -0,0 +1,183 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// FieldDiff is a single field that differs between the current and the desired object
+// Path uses the JSON field names, e.g. rules[0].criteria.min_severity
+type FieldDiff struct {
+	Path    string
+	Current interface{}
+	Desired interface{}
+}
+
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %s => %s", d.Path, formatValue(d.Current), formatValue(d.Desired))
+}
+
+// diffOptions lists the fields Xray manages itself, as paths with [] for any index, e.g. rules[].priority
+type diffOptions struct {
+	// ignored fields are never compared
+	ignored []string
+
+	// defaulted fields are filled in by Xray when the desired object leaves them unset, they are only compared when
+	// the desired object sets them
+	defaulted []string
+}
+
+// indexes matches the indexes of a field path
+var indexes = regexp.MustCompile(`\[\d+\]`)
+
+func (o diffOptions) skip(path string, desiredSet bool) bool {
+	field := indexes.ReplaceAllString(path, "[]")
+	for _, f := range o.ignored {
+		if f == field {
+			return true
+		}
+	}
+
+	if desiredSet {
+		return false
+	}
+
+	for _, f := range o.defaulted {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diff compares the JSON representation of two objects. Fields set on either side are compared, so a field set in
+// Xray but removed from the desired object shows up as a difference. An unset field and a zero value, such as false,
+// 0, "" or [], are the same, since Xray returns defaults for the fields it was not sent
+func diff(current interface{}, desired interface{}, opts diffOptions) ([]FieldDiff, error) {
+	c, err := toJSONValue(current)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := toJSONValue(desired)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FieldDiff
+	walk("", c, d, opts, &diffs)
+	return diffs, nil
+}
+
+func walk(path string, current interface{}, desired interface{}, opts diffOptions, diffs *[]FieldDiff) {
+	if isZero(current) && isZero(desired) {
+		return
+	}
+
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		c, ok := current.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, FieldDiff{Path: path, Current: current, Desired: desired})
+			return
+		}
+
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		for k := range c {
+			if _, ok := d[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			_, set := d[k]
+			if opts.skip(join(path, k), set) {
+				continue
+			}
+
+			walk(join(path, k), c[k], d[k], opts, diffs)
+		}
+	case []interface{}:
+		c, ok := current.([]interface{})
+		if !ok || len(c) != len(d) {
+			*diffs = append(*diffs, FieldDiff{Path: path, Current: current, Desired: desired})
+			return
+		}
+
+		for i := range d {
+			walk(fmt.Sprintf("%s[%d]", path, i), c[i], d[i], opts, diffs)
+		}
+	default:
+		if !reflect.DeepEqual(current, desired) {
+			*diffs = append(*diffs, FieldDiff{Path: path, Current: current, Desired: desired})
+		}
+	}
+}
+
+// isZero reports whether a JSON value is unset or a zero value, objects are zero when all their fields are
+func isZero(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, field := range v {
+			if !isZero(field) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func join(path string, field string) string {
+	if path == "" {
+		return field
+	}
+
+	return path + "." + field
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,343 @@
+// Package reconcile computes and applies the changes needed to bring the policies and watches of an Xray instance
+// to a desired state
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/xero-oss/go-xray/xray/v1"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+// Kinds of object handled by the reconciler
+const (
+	KindPolicy = "policy"
+	KindWatch  = "watch"
+)
+
+// Actions a change can perform
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// policyDiffOptions lists the policy fields set by Xray, author, created and modified are never part of the desired
+// state and Xray numbers the rules when they are not given a priority
+var policyDiffOptions = diffOptions{
+	ignored:   []string{"author", "created", "modified"},
+	defaulted: []string{"rules[].priority"},
+}
+
+// watchDiffOptions lists the watch fields set by Xray, watches are activated and resources use the default binary
+// manager when not set otherwise
+var watchDiffOptions = diffOptions{
+	ignored:   []string{"general_data.id"},
+	defaulted: []string{"general_data.active", "project_resources.resources[].bin_mgr_id"},
+}
+
+// PoliciesAPI is implemented by v1.PoliciesService
+type PoliciesAPI interface {
+	ListPolicies(ctx context.Context) (*[]v1.Policy, *http.Response, error)
+	CreatePolicy(ctx context.Context, policy *v1.Policy) (*http.Response, error)
+	UpdatePolicy(ctx context.Context, name string, policy *v1.Policy) (*http.Response, error)
+	DeletePolicy(ctx context.Context, name string) (*http.Response, error)
+}
+
+// WatchesAPI is implemented by v2.WatchesService
+type WatchesAPI interface {
+	ListWatches(ctx context.Context) (*[]v2.Watch, *http.Response, error)
+	CreateWatch(ctx context.Context, watch *v2.Watch) (*http.Response, error)
+	UpdateWatch(ctx context.Context, name string, watch *v2.Watch) (*http.Response, error)
+	DeleteWatch(ctx context.Context, name string) (*http.Response, error)
+}
+
+// State is a set of policies and watches
+type State struct {
+	Policies []v1.Policy
+	Watches  []v2.Watch
+}
+
+// Change is a single operation of a plan. Policy or Watch holds the desired object for creates and updates
+type Change struct {
+	Kind   string
+	Action string
+	Name   string
+	Diffs  []FieldDiff
+	Policy *v1.Policy
+	Watch  *v2.Watch
+}
+
+// Plan is the ordered list of changes needed to reach the desired state
+type Plan struct {
+	Changes []Change
+}
+
+// Empty reports whether the plan has nothing to do
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String renders the plan, one change per line followed by its field diffs
+func (p *Plan) String() string {
+	var buf bytes.Buffer
+	for _, c := range p.Changes {
+		fmt.Fprintf(&buf, "%s %s %q\n", c.Action, c.Kind, c.Name)
+		for _, d := range c.Diffs {
+			fmt.Fprintf(&buf, "    %s\n", d.String())
+		}
+	}
+
+	return buf.String()
+}
+
+// Reconciler plans and applies changes through the policies and watches APIs
+type Reconciler struct {
+	Policies PoliciesAPI
+	Watches  WatchesAPI
+
+	// Prune deletes the policies and watches that are not part of the desired state
+	Prune bool
+}
+
+// New creates a Reconciler, usually with client.V1.Policies and client.V2.Watches
+func New(policies PoliciesAPI, watches WatchesAPI) *Reconciler {
+	return &Reconciler{Policies: policies, Watches: watches}
+}
+
+// Current lists the policies and watches of the instance
+func (r *Reconciler) Current(ctx context.Context) (*State, error) {
+	policies, _, err := r.Policies.ListPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watches, _, err := r.Watches.ListWatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &State{Policies: *policies, Watches: *watches}, nil
+}
+
+// Plan lists the current state of the instance and computes the changes needed to reach the desired state
+func (r *Reconciler) Plan(ctx context.Context, desired *State) (*Plan, error) {
+	current, err := r.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputePlan(current, desired, r.Prune)
+}
+
+// ComputePlan computes the changes from current to desired. Changes are ordered so that they can be applied safely:
+// policies are created and updated before the watches that reference them, watches are updated and deleted, which
+// unassigns their policies, before the policies are deleted
+// It returns an error if a desired object has no name, a name is duplicated or a watch references an unknown policy
+func ComputePlan(current *State, desired *State, prune bool) (*Plan, error) {
+	currentPolicies, err := indexPolicies(current.Policies)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredPolicies, err := indexPolicies(desired.Policies)
+	if err != nil {
+		return nil, err
+	}
+
+	currentWatches, err := indexWatches(current.Watches)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredWatches, err := indexWatches(desired.Watches)
+	if err != nil {
+		return nil, err
+	}
+
+	var policyCreates, policyUpdates, policyDeletes []Change
+	for _, name := range policyNames(desiredPolicies) {
+		policy := desiredPolicies[name]
+
+		existing, ok := currentPolicies[name]
+		if !ok {
+			policyCreates = append(policyCreates, Change{Kind: KindPolicy, Action: ActionCreate, Name: name, Policy: policy})
+			continue
+		}
+
+		diffs, err := diff(existing, policy, policyDiffOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(diffs) > 0 {
+			policyUpdates = append(policyUpdates, Change{Kind: KindPolicy, Action: ActionUpdate, Name: name, Diffs: diffs, Policy: policy})
+		}
+	}
+
+	if prune {
+		for _, name := range policyNames(currentPolicies) {
+			if _, ok := desiredPolicies[name]; !ok {
+				policyDeletes = append(policyDeletes, Change{Kind: KindPolicy, Action: ActionDelete, Name: name})
+			}
+		}
+	}
+
+	var watchCreates, watchUpdates, watchDeletes []Change
+	for _, name := range watchNames(desiredWatches) {
+		watch := desiredWatches[name]
+
+		if err := checkAssignedPolicies(watch, currentPolicies, desiredPolicies, prune); err != nil {
+			return nil, err
+		}
+
+		existing, ok := currentWatches[name]
+		if !ok {
+			watchCreates = append(watchCreates, Change{Kind: KindWatch, Action: ActionCreate, Name: name, Watch: watch})
+			continue
+		}
+
+		diffs, err := diff(existing, watch, watchDiffOptions)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(diffs) > 0 {
+			watchUpdates = append(watchUpdates, Change{Kind: KindWatch, Action: ActionUpdate, Name: name, Diffs: diffs, Watch: watch})
+		}
+	}
+
+	if prune {
+		for _, name := range watchNames(currentWatches) {
+			if _, ok := desiredWatches[name]; !ok {
+				watchDeletes = append(watchDeletes, Change{Kind: KindWatch, Action: ActionDelete, Name: name})
+			}
+		}
+	}
+
+	plan := &Plan{}
+	for _, changes := range [][]Change{policyCreates, policyUpdates, watchUpdates, watchCreates, watchDeletes, policyDeletes} {
+		plan.Changes = append(plan.Changes, changes...)
+	}
+
+	return plan, nil
+}
+
+// Apply performs the changes of the plan in order, stopping at the first error
+// It returns the number of changes applied and the error, wrapped with the change that failed
+func (r *Reconciler) Apply(ctx context.Context, plan *Plan) (int, error) {
+	for i, c := range plan.Changes {
+		if err := r.apply(ctx, c); err != nil {
+			return i, fmt.Errorf("%s %s %q: %s", c.Action, c.Kind, c.Name, err.Error())
+		}
+	}
+
+	return len(plan.Changes), nil
+}
+
+func (r *Reconciler) apply(ctx context.Context, c Change) error {
+	var err error
+
+	switch c.Kind + "/" + c.Action {
+	case KindPolicy + "/" + ActionCreate:
+		_, err = r.Policies.CreatePolicy(ctx, c.Policy)
+	case KindPolicy + "/" + ActionUpdate:
+		_, err = r.Policies.UpdatePolicy(ctx, c.Name, c.Policy)
+	case KindPolicy + "/" + ActionDelete:
+		_, err = r.Policies.DeletePolicy(ctx, c.Name)
+	case KindWatch + "/" + ActionCreate:
+		_, err = r.Watches.CreateWatch(ctx, c.Watch)
+	case KindWatch + "/" + ActionUpdate:
+		_, err = r.Watches.UpdateWatch(ctx, c.Name, c.Watch)
+	case KindWatch + "/" + ActionDelete:
+		_, err = r.Watches.DeleteWatch(ctx, c.Name)
+	default:
+		err = fmt.Errorf("unsupported change")
+	}
+
+	return err
+}
+
+func checkAssignedPolicies(watch *v2.Watch, current map[string]*v1.Policy, desired map[string]*v1.Policy, prune bool) error {
+	if watch.AssignedPolicies == nil {
+		return nil
+	}
+
+	for _, assigned := range *watch.AssignedPolicies {
+		if assigned.Name == nil {
+			continue
+		}
+
+		if _, ok := desired[*assigned.Name]; ok {
+			continue
+		}
+
+		if _, ok := current[*assigned.Name]; ok && !prune {
+			continue
+		}
+
+		return fmt.Errorf("watch %q is assigned policy %q which is not part of the desired state", *watch.GeneralData.Name, *assigned.Name)
+	}
+
+	return nil
+}
+
+func indexPolicies(policies []v1.Policy) (map[string]*v1.Policy, error) {
+	index := make(map[string]*v1.Policy, len(policies))
+	for i := range policies {
+		p := &policies[i]
+		if p.Name == nil || *p.Name == "" {
+			return nil, fmt.Errorf("policy %d has no name", i)
+		}
+
+		if _, ok := index[*p.Name]; ok {
+			return nil, fmt.Errorf("policy %q is defined more than once", *p.Name)
+		}
+
+		index[*p.Name] = p
+	}
+
+	return index, nil
+}
+
+func indexWatches(watches []v2.Watch) (map[string]*v2.Watch, error) {
+	index := make(map[string]*v2.Watch, len(watches))
+	for i := range watches {
+		w := &watches[i]
+		if w.GeneralData == nil || w.GeneralData.Name == nil || *w.GeneralData.Name == "" {
+			return nil, fmt.Errorf("watch %d has no name", i)
+		}
+
+		if _, ok := index[*w.GeneralData.Name]; ok {
+			return nil, fmt.Errorf("watch %q is defined more than once", *w.GeneralData.Name)
+		}
+
+		index[*w.GeneralData.Name] = w
+	}
+
+	return index, nil
+}
+
+func policyNames(policies map[string]*v1.Policy) []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func watchNames(watches map[string]*v2.Watch) []string {
+	names := make([]string, 0, len(watches))
+	for name := range watches {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
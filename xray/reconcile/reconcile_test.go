@@ -0,0 +1,218 @@
+package reconcile
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/v1"
+	"github.com/xero-oss/go-xray/xray/v2"
+)
+
+type fakeAPI struct {
+	policies []v1.Policy
+	watches  []v2.Watch
+	calls    []string
+}
+
+func (f *fakeAPI) ListPolicies(ctx context.Context) (*[]v1.Policy, *http.Response, error) {
+	return &f.policies, nil, nil
+}
+
+func (f *fakeAPI) CreatePolicy(ctx context.Context, policy *v1.Policy) (*http.Response, error) {
+	f.calls = append(f.calls, "create policy "+*policy.Name)
+	return nil, nil
+}
+
+func (f *fakeAPI) UpdatePolicy(ctx context.Context, name string, policy *v1.Policy) (*http.Response, error) {
+	f.calls = append(f.calls, "update policy "+name)
+	return nil, nil
+}
+
+func (f *fakeAPI) DeletePolicy(ctx context.Context, name string) (*http.Response, error) {
+	f.calls = append(f.calls, "delete policy "+name)
+	return nil, nil
+}
+
+func (f *fakeAPI) ListWatches(ctx context.Context) (*[]v2.Watch, *http.Response, error) {
+	return &f.watches, nil, nil
+}
+
+func (f *fakeAPI) CreateWatch(ctx context.Context, watch *v2.Watch) (*http.Response, error) {
+	f.calls = append(f.calls, "create watch "+*watch.GeneralData.Name)
+	return nil, nil
+}
+
+func (f *fakeAPI) UpdateWatch(ctx context.Context, name string, watch *v2.Watch) (*http.Response, error) {
+	f.calls = append(f.calls, "update watch "+name)
+	return nil, nil
+}
+
+func (f *fakeAPI) DeleteWatch(ctx context.Context, name string) (*http.Response, error) {
+	f.calls = append(f.calls, "delete watch "+name)
+	return nil, nil
+}
+
+func newPolicy(name string, severity string) v1.Policy {
+	return v1.Policy{
+		Name: v1.String(name),
+		Type: v1.String("security"),
+		Rules: &[]v1.PolicyRule{
+			{
+				Name:     v1.String(name + "-rule"),
+				Criteria: &v1.PolicyRuleCriteria{MinimumSeverity: v1.String(severity)},
+			},
+		},
+	}
+}
+
+func newWatch(name string, policies ...string) v2.Watch {
+	var assigned []v2.WatchAssignedPolicy
+	for _, p := range policies {
+		assigned = append(assigned, v2.WatchAssignedPolicy{Name: v2.String(p), Type: v2.String("security")})
+	}
+
+	return v2.Watch{
+		GeneralData:      &v2.WatchGeneralData{Name: v2.String(name)},
+		AssignedPolicies: &assigned,
+	}
+}
+
+func TestReconcilerPlan_ordersChanges(t *testing.T) {
+	existing := newPolicy("keep", "High")
+	existing.Author = v1.String("admin")
+	existing.Created = v1.String("2019-01-01T00:00:00Z")
+
+	api := &fakeAPI{
+		policies: []v1.Policy{existing, newPolicy("old", "Low"), newPolicy("changed", "Low")},
+		watches:  []v2.Watch{newWatch("prod", "old"), newWatch("stale", "keep")},
+	}
+
+	desired := &State{
+		Policies: []v1.Policy{newPolicy("keep", "High"), newPolicy("changed", "High"), newPolicy("new", "Medium")},
+		Watches:  []v2.Watch{newWatch("prod", "new"), newWatch("dev", "keep")},
+	}
+
+	r := New(api, api)
+	r.Prune = true
+
+	plan, err := r.Plan(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if _, err := r.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	expected := []string{
+		"create policy new",
+		"update policy changed",
+		"update watch prod",
+		"create watch dev",
+		"delete watch stale",
+		"delete policy old",
+	}
+
+	if strings.Join(api.calls, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected calls:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), strings.Join(api.calls, "\n"))
+	}
+
+	update := plan.Changes[1]
+	if len(update.Diffs) != 1 || update.Diffs[0].Path != "rules[0].criteria.min_severity" {
+		t.Errorf("Expected a single min_severity diff but got: %v", update.Diffs)
+	}
+
+	if !strings.Contains(plan.String(), `rules[0].criteria.min_severity: "Low" => "High"`) {
+		t.Errorf("Expected the plan to render the field diff but got:\n%s", plan.String())
+	}
+}
+
+func TestComputePlan_withoutPrune(t *testing.T) {
+	current := &State{Policies: []v1.Policy{newPolicy("other", "Low")}}
+	desired := &State{Watches: []v2.Watch{newWatch("prod", "other")}}
+
+	plan, err := ComputePlan(current, desired, false)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if len(plan.Changes) != 1 || plan.Changes[0].Action != ActionCreate {
+		t.Errorf("Expected a single watch creation but got: %v", plan.Changes)
+	}
+
+	if _, err := ComputePlan(current, desired, true); err == nil {
+		t.Errorf("Expected an error for a watch assigned a pruned policy but got nil")
+	}
+}
+
+func TestComputePlan_duplicateNames(t *testing.T) {
+	desired := &State{Policies: []v1.Policy{newPolicy("p", "Low"), newPolicy("p", "High")}}
+
+	if _, err := ComputePlan(&State{}, desired, false); err == nil {
+		t.Errorf("Expected an error for duplicated policies but got nil")
+	}
+}
+
+func TestDiff_removedFields(t *testing.T) {
+	current := newPolicy("high", "High")
+	current.Description = v1.String("Set in the UI")
+	current.Author = v1.String("admin")
+	(*current.Rules)[0].Actions = &v1.PolicyRuleActions{Mails: &[]string{"security@example.com"}}
+
+	desired := newPolicy("high", "High")
+
+	diffs, err := diff(current, desired, policyDiffOptions)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	var paths []string
+	for _, d := range diffs {
+		paths = append(paths, d.String())
+	}
+
+	expected := `description: "Set in the UI" => <unset>,rules[0].actions: {"mails":["security@example.com"]} => <unset>`
+	if got := strings.Join(paths, ","); got != expected {
+		t.Errorf("Expected the diffs %s but got: %s", expected, got)
+	}
+}
+
+func TestComputePlan_serverDefaults(t *testing.T) {
+	policy := newPolicy("high", "High")
+	policy.Author = v1.String("admin")
+	policy.Modified = v1.String("2019-01-01T00:00:00Z")
+	(*policy.Rules)[0].Priority = xray.Int(1)
+	(*policy.Rules)[0].Actions = &v1.PolicyRuleActions{
+		Mails:         &[]string{},
+		FailBuild:     xray.Bool(false),
+		BlockDownload: &v1.BlockDownloadSettings{Unscanned: xray.Bool(false), Active: xray.Bool(false)},
+	}
+
+	watch := newWatch("prod", "high")
+	watch.GeneralData.Active = xray.Bool(true)
+	watch.ProjectResources = &v2.WatchProjectResources{
+		Resources: &[]v2.WatchProjectResource{
+			{Type: v2.String("repository"), Name: v2.String("libs"), BinaryManagerId: v2.String("default"), Filters: &[]v2.WatchFilter{}},
+		},
+	}
+
+	desiredWatch := newWatch("prod", "high")
+	desiredWatch.ProjectResources = &v2.WatchProjectResources{
+		Resources: &[]v2.WatchProjectResource{{Type: v2.String("repository"), Name: v2.String("libs")}},
+	}
+
+	current := &State{Policies: []v1.Policy{policy}, Watches: []v2.Watch{watch}}
+	desired := &State{Policies: []v1.Policy{newPolicy("high", "High")}, Watches: []v2.Watch{desiredWatch}}
+
+	plan, err := ComputePlan(current, desired, true)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if len(plan.Changes) != 0 {
+		t.Errorf("Expected no changes but got:\n%s", plan.String())
+	}
+}
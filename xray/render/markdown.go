@@ -0,0 +1,129 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxMarkdownArtifacts is the number of artifacts listed per issue and in the impacted artifacts section
+const maxMarkdownArtifacts = 10
+
+// WriteMarkdown writes the report as Markdown suitable for pull request comments
+func (r *Report) WriteMarkdown(w io.Writer, opts Options) error {
+	bw := bufio.NewWriter(w)
+
+	title := "Xray scan results"
+	if r.Title != "" {
+		title += ": " + r.Title
+	}
+	fmt.Fprintf(bw, "## %s\n\n", mdEscape(title))
+
+	if len(r.Issues) == 0 {
+		fmt.Fprint(bw, "No issues found.\n\n")
+	} else {
+		fmt.Fprintf(bw, "**%d issue(s)**: %s\n\n", len(r.Issues), r.countsLine())
+	}
+
+	if r.FailBuild {
+		fmt.Fprint(bw, "> **Xray policies require the build to fail.**\n\n")
+	}
+
+	if len(r.Issues) > 0 {
+		limit := maxIssues(opts)
+
+		fmt.Fprint(bw, "### Top issues\n\n")
+		fmt.Fprintln(bw, "| Severity | ID | Type | Summary | Impacted artifacts |")
+		fmt.Fprintln(bw, "|---|---|---|---|---|")
+		for i, issue := range r.Issues {
+			if i == limit {
+				break
+			}
+
+			fmt.Fprintf(bw, "| %s | %s | %s | %s | %s |\n", issue.Severity, mdIssueId(issue.Id), mdEscape(issue.Type),
+				mdEscape(issue.Summary), mdList(issue.Artifacts))
+		}
+		if len(r.Issues) > limit {
+			fmt.Fprintf(bw, "\n_... and %d more issue(s)_\n", len(r.Issues)-limit)
+		}
+		fmt.Fprintln(bw)
+
+		fmt.Fprint(bw, "### Impacted artifacts\n\n")
+		fmt.Fprintln(bw, "| Artifact | Issues | Top severity |")
+		fmt.Fprintln(bw, "|---|---|---|")
+		artifacts := r.ImpactedArtifacts()
+		for i, a := range artifacts {
+			if i == maxMarkdownArtifacts {
+				break
+			}
+			fmt.Fprintf(bw, "| `%s` | %d | %s |\n", mdCode(a.Name), a.Issues, a.TopSeverity)
+		}
+		if len(artifacts) > maxMarkdownArtifacts {
+			fmt.Fprintf(bw, "\n_... and %d more artifact(s)_\n", len(artifacts)-maxMarkdownArtifacts)
+		}
+		fmt.Fprintln(bw)
+	}
+
+	if len(r.Licenses) > 0 {
+		fmt.Fprint(bw, "### Licenses\n\n")
+		fmt.Fprintln(bw, "| License | Components |")
+		fmt.Fprintln(bw, "|---|---|")
+		for _, l := range r.Licenses {
+			name := mdEscape(l.Name)
+			if l.FullName != "" && l.FullName != l.Name {
+				name += " (" + mdEscape(l.FullName) + ")"
+			}
+			fmt.Fprintf(bw, "| %s | %d |\n", name, len(l.Components))
+		}
+		fmt.Fprintln(bw)
+	}
+
+	if r.MoreDetailsUrl != "" {
+		fmt.Fprintf(bw, "[More details in Xray](%s)\n", r.MoreDetailsUrl)
+	}
+
+	return bw.Flush()
+}
+
+// mdIssueId links CVEs to the NVD
+func mdIssueId(id string) string {
+	if id == "" {
+		return "-"
+	}
+
+	if strings.HasPrefix(strings.ToUpper(id), "CVE-") {
+		return fmt.Sprintf("[%s](https://nvd.nist.gov/vuln/detail/%s)", id, strings.ToUpper(id))
+	}
+
+	return mdEscape(id)
+}
+
+func mdList(values []string) string {
+	var items []string
+	for i, v := range values {
+		if i == maxMarkdownArtifacts {
+			items = append(items, fmt.Sprintf("and %d more", len(values)-maxMarkdownArtifacts))
+			break
+		}
+		items = append(items, "`"+mdCode(v)+"`")
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// mdEscape escapes the characters that break a table cell or start Markdown formatting
+func mdEscape(s string) string {
+	s = strings.Replace(s, "\n", " ", -1)
+	for _, c := range []string{`\`, "|", "*", "_", "`", "[", "]", "<", ">"} {
+		s = strings.Replace(s, c, `\`+c, -1)
+	}
+
+	return s
+}
+
+// mdCode makes a value safe to put between backticks in a table cell
+func mdCode(s string) string {
+	s = strings.Replace(s, "`", "'", -1)
+	return strings.Replace(s, "|", `\|`, -1)
+}
@@ -0,0 +1,313 @@
+// Package render writes build scan results and summaries as human readable reports for terminals and Markdown
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// DefaultMaxIssues is the number of issues listed when Options.MaxIssues is not set
+const DefaultMaxIssues = 20
+
+// Options configures the renderers
+type Options struct {
+	// MaxIssues is the number of issues listed, the most severe first
+	MaxIssues int
+
+	// Color uses ANSI colors for the severities, terminal only
+	Color bool
+}
+
+// Issue is an issue with the artifacts and watches it was reported for
+type Issue struct {
+	Severity  string
+	Type      string
+	Id        string
+	Summary   string
+	Artifacts []string
+	Watches   []string
+}
+
+// License is a license with the components it was found in
+type License struct {
+	Name       string
+	FullName   string
+	Components []string
+}
+
+// Report is the rendered model of a build scan or a summary. Issues are sorted from the most severe
+type Report struct {
+	Title          string
+	MoreDetailsUrl string
+	FailBuild      bool
+	Issues         []Issue
+	Licenses       []License
+}
+
+// FromScanBuildOutput builds the report of a build scan, the issues reported by several watches are merged
+func FromScanBuildOutput(output *v1.ScanBuildOutput) *Report {
+	r := &Report{}
+	if s := output.Summary; s != nil {
+		r.Title = ptr.StringValue(s.Message)
+		r.MoreDetailsUrl = ptr.StringValue(s.MoreDetailsUrl)
+		r.FailBuild = s.FailBuild != nil && *s.FailBuild
+	}
+
+	m := newMerger()
+	if output.Alerts != nil {
+		for _, alert := range *output.Alerts {
+			if alert.Issues == nil {
+				continue
+			}
+
+			for _, issue := range *alert.Issues {
+				var artifacts []string
+				if issue.ImpactedArtifacts != nil {
+					for _, a := range *issue.ImpactedArtifacts {
+						name := ptr.StringValue(a.DisplayName)
+						if name == "" {
+							name = ptr.StringValue(a.Name)
+						}
+						artifacts = append(artifacts, name)
+					}
+				}
+
+				m.add(Issue{
+					Severity: severity.Normalize(ptr.StringValue(issue.Severity)),
+					Type:     ptr.StringValue(issue.Type),
+					Id:       ptr.StringValue(issue.CVE),
+					Summary:  ptr.StringValue(issue.Summary),
+				}, artifacts, ptr.StringValue(alert.WatchName))
+			}
+		}
+	}
+	r.Issues = m.issues()
+
+	if output.Licenses != nil {
+		for _, l := range *output.Licenses {
+			r.Licenses = append(r.Licenses, newLicense(l.Name, l.FullName, l.Components))
+		}
+	}
+	sortLicenses(r.Licenses)
+
+	return r
+}
+
+// FromSummary builds the report of an artifact or build summary
+func FromSummary(summary *v1.Summary) *Report {
+	r := &Report{}
+	if summary.Artifacts == nil {
+		return r
+	}
+
+	r.Title = fmt.Sprintf("%d artifact(s)", len(*summary.Artifacts))
+
+	m := newMerger()
+	licenses := map[string]int{}
+	for _, artifact := range *summary.Artifacts {
+		var name string
+		if artifact.General != nil {
+			name = ptr.StringValue(artifact.General.Name)
+			if name == "" {
+				name = ptr.StringValue(artifact.General.Path)
+			}
+		}
+
+		if artifact.Issues != nil {
+			for _, issue := range *artifact.Issues {
+				id := ptr.StringValue(issue.IssueId)
+				if issue.Cves != nil && len(*issue.Cves) > 0 && ptr.StringValue((*issue.Cves)[0].Cve) != "" {
+					id = ptr.StringValue((*issue.Cves)[0].Cve)
+				}
+
+				m.add(Issue{
+					Severity: severity.Normalize(ptr.StringValue(issue.Severity)),
+					Type:     ptr.StringValue(issue.IssueType),
+					Id:       id,
+					Summary:  ptr.StringValue(issue.Summary),
+				}, []string{name}, "")
+			}
+		}
+
+		if artifact.Licenses != nil {
+			for _, l := range *artifact.Licenses {
+				license := newLicense(l.Name, l.FullName, l.Components)
+				if i, ok := licenses[license.Name]; ok {
+					r.Licenses[i].Components = appendUnique(r.Licenses[i].Components, license.Components...)
+					continue
+				}
+
+				licenses[license.Name] = len(r.Licenses)
+				r.Licenses = append(r.Licenses, license)
+			}
+		}
+	}
+
+	if len(*summary.Artifacts) == 1 && (*summary.Artifacts)[0].General != nil {
+		r.Title = ptr.StringValue((*summary.Artifacts)[0].General.Name)
+	}
+
+	r.Issues = m.issues()
+	sortLicenses(r.Licenses)
+	return r
+}
+
+// Counts returns the number of issues per severity
+func (r *Report) Counts() map[string]int {
+	counts := map[string]int{}
+	for _, i := range r.Issues {
+		counts[i.Severity]++
+	}
+
+	return counts
+}
+
+// countsLine renders the number of issues per severity, the most severe first, e.g. 1 Critical, 2 High
+func (r *Report) countsLine() string {
+	counts := r.Counts()
+
+	var parts []string
+	for i := len(severity.All) - 1; i >= 0; i-- {
+		if n := counts[severity.All[i]]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, severity.All[i]))
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// ImpactedArtifact is an artifact with the number of issues it has
+type ImpactedArtifact struct {
+	Name        string
+	Issues      int
+	TopSeverity string
+}
+
+// ImpactedArtifacts returns the artifacts with issues and the most severe of their issues, the most impacted first
+func (r *Report) ImpactedArtifacts() []ImpactedArtifact {
+	index := map[string]int{}
+	var artifacts []ImpactedArtifact
+	for _, issue := range r.Issues {
+		for _, name := range issue.Artifacts {
+			i, ok := index[name]
+			if !ok {
+				i = len(artifacts)
+				index[name] = i
+				artifacts = append(artifacts, ImpactedArtifact{Name: name, TopSeverity: issue.Severity})
+			}
+
+			artifacts[i].Issues++
+			if severity.Compare(issue.Severity, artifacts[i].TopSeverity) > 0 {
+				artifacts[i].TopSeverity = issue.Severity
+			}
+		}
+	}
+
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		if c := severity.Compare(artifacts[i].TopSeverity, artifacts[j].TopSeverity); c != 0 {
+			return c > 0
+		}
+		return artifacts[i].Issues > artifacts[j].Issues
+	})
+
+	return artifacts
+}
+
+// merger merges the issues with the same type, id and summary
+type merger struct {
+	index map[string]int
+	list  []Issue
+}
+
+func newMerger() *merger {
+	return &merger{index: map[string]int{}}
+}
+
+func (m *merger) add(issue Issue, artifacts []string, watch string) {
+	key := issue.Type + "|" + issue.Id + "|" + issue.Summary
+	i, ok := m.index[key]
+	if !ok {
+		i = len(m.list)
+		m.index[key] = i
+		m.list = append(m.list, issue)
+	}
+
+	m.list[i].Artifacts = appendUnique(m.list[i].Artifacts, artifacts...)
+	if watch != "" {
+		m.list[i].Watches = appendUnique(m.list[i].Watches, watch)
+	}
+	if severity.Compare(issue.Severity, m.list[i].Severity) > 0 {
+		m.list[i].Severity = issue.Severity
+	}
+}
+
+func (m *merger) issues() []Issue {
+	issues := append([]Issue(nil), m.list...)
+	sort.SliceStable(issues, func(i, j int) bool {
+		return severity.Compare(issues[i].Severity, issues[j].Severity) > 0
+	})
+
+	return issues
+}
+
+func newLicense(name *string, fullName *string, components *[]string) License {
+	l := License{Name: ptr.StringValue(name), FullName: ptr.StringValue(fullName)}
+	if components != nil {
+		l.Components = appendUnique(nil, *components...)
+	}
+
+	return l
+}
+
+// sortLicenses sorts the licenses by number of components, then by name
+func sortLicenses(licenses []License) {
+	sort.SliceStable(licenses, func(i, j int) bool {
+		if len(licenses[i].Components) != len(licenses[j].Components) {
+			return len(licenses[i].Components) > len(licenses[j].Components)
+		}
+		return licenses[i].Name < licenses[j].Name
+	})
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+func maxIssues(opts Options) int {
+	if opts.MaxIssues <= 0 {
+		return DefaultMaxIssues
+	}
+
+	return opts.MaxIssues
+}
+
+func truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+
+	return string([]rune(s)[:n-3]) + "..."
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/internal/golden"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+func buildReport(t *testing.T) *Report {
+	var output v1.ScanBuildOutput
+	golden.ReadJSON(t, "testdata/build_scan.json", &output)
+	return FromScanBuildOutput(&output)
+}
+
+func summaryReport(t *testing.T) *Report {
+	var summary v1.Summary
+	golden.ReadJSON(t, "testdata/artifact_summary.json", &summary)
+	return FromSummary(&summary)
+}
+
+func TestWriteTerminal_golden(t *testing.T) {
+	for name, r := range map[string]*Report{"build_scan.txt": buildReport(t), "artifact_summary.txt": summaryReport(t)} {
+		var buf bytes.Buffer
+		if err := r.WriteTerminal(&buf, Options{}); err != nil {
+			t.Fatalf("Got the following error: %s", err.Error())
+		}
+
+		golden.Check(t, "testdata/"+name, buf.Bytes())
+	}
+}
+
+func TestWriteMarkdown_golden(t *testing.T) {
+	for name, r := range map[string]*Report{"build_scan.md": buildReport(t), "artifact_summary.md": summaryReport(t)} {
+		var buf bytes.Buffer
+		if err := r.WriteMarkdown(&buf, Options{}); err != nil {
+			t.Fatalf("Got the following error: %s", err.Error())
+		}
+
+		golden.Check(t, "testdata/"+name, buf.Bytes())
+	}
+}
+
+func TestFromSummary(t *testing.T) {
+	r := summaryReport(t)
+
+	if len(r.Issues) != 3 {
+		t.Fatalf("Expected 3 issues but got: %d", len(r.Issues))
+	}
+
+	if r.Issues[0].Id != "CVE-2017-5638" {
+		t.Errorf("Expected the CVE as id of the first issue but got: %s", r.Issues[0].Id)
+	}
+
+	for i := 1; i < len(r.Issues); i++ {
+		if severity.Compare(r.Issues[i-1].Severity, r.Issues[i].Severity) < 0 {
+			t.Errorf("Expected the issues sorted by severity but got: %s before %s", r.Issues[i-1].Severity, r.Issues[i].Severity)
+		}
+	}
+
+	if r.Title != "struts2-core-2.3.30.jar" {
+		t.Errorf("Expected the artifact name as title but got: %s", r.Title)
+	}
+}
+
+func TestFromScanBuildOutput_mergesWatches(t *testing.T) {
+	issue := v1.BuildScanIssue{
+		CVE:      v1.String("CVE-2018-16487"),
+		Severity: v1.String("High"),
+		Summary:  v1.String("Prototype pollution in lodash"),
+		Type:     v1.String("security"),
+		ImpactedArtifacts: &[]v1.BuildScanArtifact{
+			{DisplayName: v1.String("app:42")},
+		},
+	}
+	output := &v1.ScanBuildOutput{
+		Alerts: &[]v1.BuileScanAlert{
+			{WatchName: v1.String("prod"), Issues: &[]v1.BuildScanIssue{issue}},
+			{WatchName: v1.String("dev"), Issues: &[]v1.BuildScanIssue{issue}},
+		},
+	}
+
+	r := FromScanBuildOutput(output)
+	if len(r.Issues) != 1 {
+		t.Fatalf("Expected 1 issue but got: %d", len(r.Issues))
+	}
+
+	if strings.Join(r.Issues[0].Watches, ",") != "prod,dev" {
+		t.Errorf("Expected the watches prod,dev but got: %v", r.Issues[0].Watches)
+	}
+
+	if len(r.Issues[0].Artifacts) != 1 {
+		t.Errorf("Expected 1 artifact but got: %v", r.Issues[0].Artifacts)
+	}
+}
+
+func TestMaxIssues(t *testing.T) {
+	r := buildReport(t)
+
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf, Options{MaxIssues: 1}); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !strings.Contains(buf.String(), "... and 1 more issue(s)") {
+		t.Errorf("Expected the remaining issues to be counted but got:\n%s", buf.String())
+	}
+}
+
+func TestWriteTerminal_color(t *testing.T) {
+	r := buildReport(t)
+
+	var buf bytes.Buffer
+	if err := r.WriteTerminal(&buf, Options{Color: true}); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !strings.Contains(buf.String(), ansiColors[severity.High]+"HIGH"+ansiReset) {
+		t.Errorf("Expected a colored severity heading but got:\n%q", buf.String())
+	}
+}
+
+func TestMdEscape(t *testing.T) {
+	if got := mdEscape("a|b_c\nd"); got != `a\|b\_c d` {
+		t.Errorf("Expected the value escaped but got: %s", got)
+	}
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/xero-oss/go-xray/xray/severity"
+)
+
+// ANSI colors of the severities
+var ansiColors = map[string]string{
+	severity.Critical: "\x1b[1;31m",
+	severity.High:     "\x1b[31m",
+	severity.Medium:   "\x1b[33m",
+	severity.Low:      "\x1b[36m",
+}
+
+const ansiReset = "\x1b[0m"
+
+// maxTerminalWidth bounds the summaries and the artifact lists of the terminal tables
+const maxTerminalWidth = 60
+
+// WriteTerminal writes the report as tables grouped by severity, the most severe first
+func (r *Report) WriteTerminal(w io.Writer, opts Options) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if r.Title != "" {
+		fmt.Fprintln(tw, r.Title)
+	}
+
+	if len(r.Issues) == 0 {
+		fmt.Fprintln(tw, "No issues found")
+	} else {
+		fmt.Fprintf(tw, "%d issue(s): %s\n", len(r.Issues), r.countsLine())
+	}
+
+	if r.FailBuild {
+		fmt.Fprintln(tw, "Xray policies require the build to fail")
+	}
+
+	limit := maxIssues(opts)
+	counts := r.Counts()
+	group := ""
+	for i, issue := range r.Issues {
+		if i == limit {
+			fmt.Fprintf(tw, "\n... and %d more issue(s)\n", len(r.Issues)-limit)
+			break
+		}
+
+		if issue.Severity != group {
+			group = issue.Severity
+			fmt.Fprintf(tw, "\n%s (%d)\n", colorize(strings.ToUpper(group), group, opts.Color), counts[group])
+			fmt.Fprintln(tw, "  ID\tTYPE\tSUMMARY\tARTIFACTS")
+		}
+
+		id := issue.Id
+		if id == "" {
+			id = "-"
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", id, issue.Type, truncate(issue.Summary, maxTerminalWidth),
+			truncate(strings.Join(issue.Artifacts, ", "), maxTerminalWidth))
+	}
+
+	if len(r.Licenses) > 0 {
+		fmt.Fprintln(tw, "\nLICENSES")
+		fmt.Fprintln(tw, "  NAME\tCOMPONENTS")
+		for _, l := range r.Licenses {
+			fmt.Fprintf(tw, "  %s\t%d\n", l.Name, len(l.Components))
+		}
+	}
+
+	if r.MoreDetailsUrl != "" {
+		fmt.Fprintf(tw, "\nMore details: %s\n", r.MoreDetailsUrl)
+	}
+
+	return tw.Flush()
+}
+
+func colorize(s string, sev string, color bool) string {
+	c, ok := ansiColors[sev]
+	if !color || !ok {
+		return s
+	}
+
+	return c + s + ansiReset
+}
@@ -0,0 +1,329 @@
+// Package sarif converts Xray build scan and artifact summary results into SARIF 2.1.0 logs
+package sarif
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/xray/severity"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	toolName = "JFrog Xray"
+	toolUri  = "https://jfrog.com/xray/"
+)
+
+// Result levels
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNote    = "note"
+)
+
+// securitySeverities are the scores reported in the security-severity property of the rules, which code scanning
+// dashboards use to rank results
+var securitySeverities = map[string]string{
+	severity.Critical: "9.5",
+	severity.High:     "8.0",
+	severity.Medium:   "5.5",
+	severity.Low:      "3.0",
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type ArtifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+}
+
+type LogicalLocation struct {
+	Name string `json:"name,omitempty"`
+	Kind string `json:"kind,omitempty"`
+}
+
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []LogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type Rule struct {
+	Id               string                 `json:"id"`
+	ShortDescription *Message               `json:"shortDescription,omitempty"`
+	FullDescription  *Message               `json:"fullDescription,omitempty"`
+	HelpUri          string                 `json:"helpUri,omitempty"`
+	Properties       map[string]interface{} `json:"properties,omitempty"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	InformationUri string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Result struct {
+	RuleId     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    Message                `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+}
+
+// Log is a SARIF log with a single run
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Write writes the log as indented JSON
+func (l *Log) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// builder accumulates rules and results, rules are deduplicated by id in order of first appearance
+type builder struct {
+	run   Run
+	rules map[string]int
+}
+
+func newBuilder() *builder {
+	return &builder{
+		run: Run{
+			Tool:    Tool{Driver: Driver{Name: toolName, InformationUri: toolUri, Rules: []Rule{}}},
+			Results: []Result{},
+		},
+		rules: map[string]int{},
+	}
+}
+
+func (b *builder) log() *Log {
+	return &Log{Schema: Schema, Version: Version, Runs: []Run{b.run}}
+}
+
+func (b *builder) rule(id string, sev string, issueType string, summary string, description string) int {
+	if i, ok := b.rules[id]; ok {
+		return i
+	}
+
+	r := Rule{
+		Id:         id,
+		Properties: map[string]interface{}{},
+	}
+	if summary != "" {
+		r.ShortDescription = &Message{Text: summary}
+	}
+	if description != "" {
+		r.FullDescription = &Message{Text: description}
+	}
+	if strings.HasPrefix(strings.ToUpper(id), "CVE-") {
+		r.HelpUri = "https://nvd.nist.gov/vuln/detail/" + strings.ToUpper(id)
+	}
+	if score, ok := securitySeverities[severity.Normalize(sev)]; ok && issueType == "security" {
+		r.Properties["security-severity"] = score
+	}
+	if issueType != "" {
+		r.Properties["tags"] = []string{issueType}
+	}
+
+	b.run.Tool.Driver.Rules = append(b.run.Tool.Driver.Rules, r)
+	b.rules[id] = len(b.run.Tool.Driver.Rules) - 1
+	return b.rules[id]
+}
+
+func (b *builder) add(ruleId string, ruleIndex int, sev string, text string, locations []Location, properties map[string]interface{}) {
+	b.run.Results = append(b.run.Results, Result{
+		RuleId:     ruleId,
+		RuleIndex:  ruleIndex,
+		Level:      Level(sev),
+		Message:    Message{Text: text},
+		Locations:  locations,
+		Properties: properties,
+	})
+}
+
+// Level maps an Xray severity to a SARIF result level
+func Level(sev string) string {
+	switch {
+	case severity.AtLeast(sev, severity.High):
+		return LevelError
+	case severity.AtLeast(sev, severity.Medium):
+		return LevelWarning
+	default:
+		return LevelNote
+	}
+}
+
+// FromScanBuildOutput converts the alerts of a build scan, one result per issue and impacted artifact
+func FromScanBuildOutput(output *v1.ScanBuildOutput) *Log {
+	b := newBuilder()
+	if output == nil || output.Alerts == nil {
+		return b.log()
+	}
+
+	for _, alert := range *output.Alerts {
+		if alert.Issues == nil {
+			continue
+		}
+
+		for _, issue := range *alert.Issues {
+			sev := ptr.StringValue(issue.Severity)
+			id := ruleId(ptr.StringValue(issue.CVE), "", ptr.StringValue(issue.Type), ptr.StringValue(issue.Summary))
+			index := b.rule(id, sev, ptr.StringValue(issue.Type), ptr.StringValue(issue.Summary), ptr.StringValue(issue.Description))
+
+			properties := map[string]interface{}{"severity": severity.Normalize(sev)}
+			if alert.WatchName != nil {
+				properties["watch"] = *alert.WatchName
+			}
+
+			if issue.ImpactedArtifacts == nil || len(*issue.ImpactedArtifacts) == 0 {
+				b.add(id, index, sev, message(issue.Summary, issue.Description, ""), nil, properties)
+				continue
+			}
+
+			for _, a := range *issue.ImpactedArtifacts {
+				name := ptr.StringValue(a.DisplayName)
+				if name == "" {
+					name = ptr.StringValue(a.Name)
+				}
+
+				location := Location{
+					PhysicalLocation: &PhysicalLocation{ArtifactLocation: ArtifactLocation{Uri: artifactUri(ptr.StringValue(a.Path), ptr.StringValue(a.Name))}},
+				}
+				if name != "" {
+					location.LogicalLocations = []LogicalLocation{{Name: name, Kind: "package"}}
+				}
+
+				b.add(id, index, sev, message(issue.Summary, issue.Description, name), []Location{location}, properties)
+			}
+		}
+	}
+
+	return b.log()
+}
+
+// FromSummary converts the issues of an artifact or build summary, one result per artifact issue
+func FromSummary(summary *v1.Summary) *Log {
+	b := newBuilder()
+	if summary == nil || summary.Artifacts == nil {
+		return b.log()
+	}
+
+	for _, artifact := range *summary.Artifacts {
+		if artifact.Issues == nil {
+			continue
+		}
+
+		var uri, name string
+		if artifact.General != nil {
+			uri = ptr.StringValue(artifact.General.Path)
+			name = ptr.StringValue(artifact.General.ComponentId)
+			if name == "" {
+				name = ptr.StringValue(artifact.General.Name)
+			}
+		}
+
+		for _, issue := range *artifact.Issues {
+			sev := ptr.StringValue(issue.Severity)
+
+			var cve string
+			if issue.Cves != nil && len(*issue.Cves) > 0 {
+				cve = ptr.StringValue((*issue.Cves)[0].Cve)
+			}
+
+			id := ruleId(cve, ptr.StringValue(issue.IssueId), ptr.StringValue(issue.IssueType), ptr.StringValue(issue.Summary))
+			index := b.rule(id, sev, ptr.StringValue(issue.IssueType), ptr.StringValue(issue.Summary), ptr.StringValue(issue.Description))
+
+			location := Location{}
+			if uri != "" {
+				location.PhysicalLocation = &PhysicalLocation{ArtifactLocation: ArtifactLocation{Uri: uri}}
+			}
+			if name != "" {
+				location.LogicalLocations = []LogicalLocation{{Name: name, Kind: "package"}}
+			}
+
+			properties := map[string]interface{}{"severity": severity.Normalize(sev)}
+			if issue.ImpactPath != nil && len(*issue.ImpactPath) > 0 {
+				properties["impactPath"] = *issue.ImpactPath
+			}
+
+			var locations []Location
+			if location.PhysicalLocation != nil || location.LogicalLocations != nil {
+				locations = []Location{location}
+			}
+
+			b.add(id, index, sev, message(issue.Summary, issue.Description, name), locations, properties)
+		}
+	}
+
+	return b.log()
+}
+
+// ruleId prefers the CVE, then the Xray issue id, and falls back to a stable id derived from the summary
+func ruleId(cve string, issueId string, issueType string, summary string) string {
+	if cve != "" {
+		return cve
+	}
+
+	if issueId != "" {
+		return issueId
+	}
+
+	if issueType == "" {
+		issueType = "issue"
+	}
+
+	sum := sha1.Sum([]byte(summary))
+	return fmt.Sprintf("xray-%s-%x", issueType, sum[:6])
+}
+
+func message(summary *string, description *string, artifact string) string {
+	text := ptr.StringValue(summary)
+	if text == "" {
+		text = ptr.StringValue(description)
+	}
+
+	if artifact != "" {
+		text = fmt.Sprintf("%s in %s", text, artifact)
+	}
+
+	return text
+}
+
+// artifactUri joins the path and the name of an artifact unless the path already ends with the name
+func artifactUri(p string, name string) string {
+	if name == "" || path.Base(p) == name {
+		return p
+	}
+
+	if p == "" {
+		return name
+	}
+
+	return path.Join(p, name)
+}
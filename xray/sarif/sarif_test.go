@@ -0,0 +1,58 @@
+package sarif
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xero-oss/go-xray/internal/golden"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// checkGolden writes the log and compares it with testdata/name
+func checkGolden(t *testing.T, name string, log *Log) {
+	var buf bytes.Buffer
+	if err := log.Write(&buf); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/"+name, buf.Bytes())
+}
+
+func TestFromScanBuildOutput_golden(t *testing.T) {
+	var output v1.ScanBuildOutput
+	golden.ReadJSON(t, "../testdata/build_scan.json", &output)
+
+	checkGolden(t, "build_scan.sarif", FromScanBuildOutput(&output))
+}
+
+func TestFromSummary_golden(t *testing.T) {
+	var summary v1.Summary
+	golden.ReadJSON(t, "../testdata/artifact_summary.json", &summary)
+
+	checkGolden(t, "artifact_summary.sarif", FromSummary(&summary))
+}
+
+func TestLevel(t *testing.T) {
+	cases := map[string]string{
+		"Critical": LevelError,
+		"High":     LevelError,
+		"Major":    LevelError,
+		"Medium":   LevelWarning,
+		"Low":      LevelNote,
+		"":         LevelNote,
+	}
+
+	for sev, expected := range cases {
+		if got := Level(sev); got != expected {
+			t.Errorf("Expected level %s for severity '%s' but got: %s", expected, sev, got)
+		}
+	}
+}
+
+func TestFromScanBuildOutput_empty(t *testing.T) {
+	log := FromScanBuildOutput(&v1.ScanBuildOutput{})
+
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 0 {
+		t.Errorf("Expected a single run without results but got: %v", log.Runs)
+	}
+}
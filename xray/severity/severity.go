@@ -0,0 +1,63 @@
+// Package severity ranks the severities reported by Xray
+package severity
+
+import "strings"
+
+// Severities reported by Xray, from the least to the most severe
+const (
+	Unknown     = "Unknown"
+	Information = "Information"
+	Low         = "Low"
+	Medium      = "Medium"
+	High        = "High"
+	Critical    = "Critical"
+)
+
+// ranks maps the lowercase severity names, including the legacy Minor and Major names, to their rank
+var ranks = map[string]int{
+	"unknown":     0,
+	"information": 1,
+	"low":         2,
+	"minor":       2,
+	"medium":      3,
+	"high":        4,
+	"major":       4,
+	"critical":    5,
+}
+
+// All lists the severities from the least to the most severe
+var All = []string{Unknown, Information, Low, Medium, High, Critical}
+
+// Rank returns the rank of a severity, higher is more severe. Names are case insensitive and unknown names rank as Unknown
+func Rank(s string) int {
+	return ranks[strings.ToLower(strings.TrimSpace(s))]
+}
+
+// Valid reports whether s is a severity known to Xray
+func Valid(s string) bool {
+	_, ok := ranks[strings.ToLower(strings.TrimSpace(s))]
+	return ok
+}
+
+// Normalize returns the canonical name of a severity, mapping Minor to Low and Major to High
+func Normalize(s string) string {
+	return All[Rank(s)]
+}
+
+// AtLeast reports whether s is as severe as min or more
+func AtLeast(s string, min string) bool {
+	return Rank(s) >= Rank(min)
+}
+
+// Compare returns -1, 0 or 1 when a is less, as or more severe than b
+func Compare(a string, b string) int {
+	ra, rb := Rank(a), Rank(b)
+	switch {
+	case ra < rb:
+		return -1
+	case ra > rb:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,40 @@
+package severity
+
+import "testing"
+
+func TestRank_order(t *testing.T) {
+	for i := 1; i < len(All); i++ {
+		if Rank(All[i]) <= Rank(All[i-1]) {
+			t.Errorf("Expected %s to rank above %s", All[i], All[i-1])
+		}
+	}
+}
+
+func TestNormalize_legacyNames(t *testing.T) {
+	cases := map[string]string{
+		"Minor":    Low,
+		"major":    High,
+		"CRITICAL": Critical,
+		"bogus":    Unknown,
+	}
+
+	for in, expected := range cases {
+		if got := Normalize(in); got != expected {
+			t.Errorf("Expected %s to normalize to %s but got: %s", in, expected, got)
+		}
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	if !AtLeast("High", "medium") {
+		t.Errorf("Expected High to be at least Medium")
+	}
+
+	if AtLeast("Low", "Medium") {
+		t.Errorf("Expected Low not to be at least Medium")
+	}
+
+	if Valid("bogus") {
+		t.Errorf("Expected bogus not to be a valid severity")
+	}
+}
@@ -0,0 +1,423 @@
+// Package spdx generates SPDX 2.3 documents from Xray dependency graphs and license data
+package spdx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/xero-oss/go-xray/internal/ptr"
+	"github.com/xero-oss/go-xray/internal/spdxlicense"
+	"github.com/xero-oss/go-xray/internal/uuid"
+	"github.com/xero-oss/go-xray/xray/componentid"
+	"github.com/xero-oss/go-xray/xray/graph"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+const (
+	Version     = "SPDX-2.3"
+	DataLicense = "CC0-1.0"
+	DocumentId  = "SPDXRef-DOCUMENT"
+
+	// NoAssertion is used for the fields the Xray data says nothing about
+	NoAssertion = "NOASSERTION"
+)
+
+// Relationship types
+const (
+	RelationshipDescribes = "DESCRIBES"
+	RelationshipDependsOn = "DEPENDS_ON"
+)
+
+// invalidIdChars matches the characters not allowed in SPDX element ids
+var invalidIdChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+type CreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type Checksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type ExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type Package struct {
+	Name             string        `json:"name"`
+	SPDXID           string        `json:"SPDXID"`
+	VersionInfo      string        `json:"versionInfo,omitempty"`
+	DownloadLocation string        `json:"downloadLocation"`
+	FilesAnalyzed    bool          `json:"filesAnalyzed"`
+	Checksums        []Checksum    `json:"checksums,omitempty"`
+	LicenseConcluded string        `json:"licenseConcluded"`
+	LicenseDeclared  string        `json:"licenseDeclared"`
+	CopyrightText    string        `json:"copyrightText"`
+	ExternalRefs     []ExternalRef `json:"externalRefs,omitempty"`
+
+	// licenses are the license names reported by Xray, in order of first appearance
+	licenses []string
+}
+
+type Relationship struct {
+	SpdxElementId      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
+}
+
+// ExtractedLicensingInfo describes a license that has no SPDX license id
+type ExtractedLicensingInfo struct {
+	LicenseId     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name,omitempty"`
+}
+
+// Document is an SPDX document describing the root package of a dependency graph. The converters leave
+// DocumentNamespace empty, it must be set with SetNamespace before the document is written
+type Document struct {
+	SPDXVersion                string                   `json:"spdxVersion"`
+	DataLicense                string                   `json:"dataLicense"`
+	SPDXID                     string                   `json:"SPDXID"`
+	Name                       string                   `json:"name"`
+	DocumentNamespace          string                   `json:"documentNamespace"`
+	CreationInfo               CreationInfo             `json:"creationInfo"`
+	Packages                   []Package                `json:"packages"`
+	Relationships              []Relationship           `json:"relationships"`
+	HasExtractedLicensingInfos []ExtractedLicensingInfo `json:"hasExtractedLicensingInfos,omitempty"`
+
+	// packages maps the Xray component ids to the index of their package
+	packages map[string]int
+	ids      map[string]bool
+}
+
+func newDocument(name string, rootId string, root Package) *Document {
+	d := &Document{
+		SPDXVersion: Version,
+		DataLicense: DataLicense,
+		SPDXID:      DocumentId,
+		Name:        name,
+		CreationInfo: CreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{"Organization: JFrog Xray", "Tool: go-xray"},
+		},
+		Packages:      []Package{},
+		Relationships: []Relationship{},
+		packages:      map[string]int{},
+		ids:           map[string]bool{},
+	}
+
+	ref := d.addPackage(rootId, root)
+	d.Relationships = append(d.Relationships, Relationship{SpdxElementId: DocumentId, RelationshipType: RelationshipDescribes, RelatedSpdxElement: ref})
+	return d
+}
+
+// addPackage adds the package of a component unless it is already part of the document and returns its SPDX id
+// The package URL of the component, when it has one, is added as an external reference
+func (d *Document) addPackage(componentId string, p Package) string {
+	if i, ok := d.packages[componentId]; ok {
+		return d.Packages[i].SPDXID
+	}
+
+	p.SPDXID = d.newId(componentId)
+	p.DownloadLocation = NoAssertion
+	p.LicenseConcluded = NoAssertion
+	p.LicenseDeclared = NoAssertion
+	p.CopyrightText = NoAssertion
+	if purl, err := componentid.ToPURL(componentId); err == nil {
+		p.ExternalRefs = []ExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}}
+	}
+
+	d.Packages = append(d.Packages, p)
+	d.packages[componentId] = len(d.Packages) - 1
+	return p.SPDXID
+}
+
+// newId derives a unique SPDX element id from a component id
+func (d *Document) newId(componentId string) string {
+	base := "SPDXRef-Package-" + strings.Trim(invalidIdChars.ReplaceAllString(componentId, "-"), "-")
+
+	id := base
+	for i := 2; d.ids[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	d.ids[id] = true
+	return id
+}
+
+// fromGraph builds the document of a dependency graph, the document describes the root of the graph
+func fromGraph(name string, root Package, g *graph.Graph) *Document {
+	d := newDocument(name, g.Root.Id, root)
+
+	refs := map[*graph.Node]string{g.Root: d.Packages[0].SPDXID}
+	for _, n := range g.Nodes() {
+		if n != g.Root {
+			refs[n] = d.addPackage(n.Id, Package{Name: n.Name, VersionInfo: n.Version})
+		}
+	}
+
+	for _, n := range g.Nodes() {
+		for _, dependency := range n.Dependencies {
+			d.Relationships = append(d.Relationships, Relationship{
+				SpdxElementId:      refs[n],
+				RelationshipType:   RelationshipDependsOn,
+				RelatedSpdxElement: refs[dependency],
+			})
+		}
+	}
+
+	return d
+}
+
+// FromArtifactGraph converts the dependency graph of an artifact, the document describes the artifact
+func FromArtifactGraph(output *v1.GetArtifactDependencyGraphOutput) *Document {
+	root := Package{}
+	if a := output.Artifact; a != nil {
+		root.Name = ptr.StringValue(a.Name)
+		if a.Sha256 != nil {
+			root.Checksums = append(root.Checksums, Checksum{Algorithm: "SHA256", ChecksumValue: *a.Sha256})
+		}
+		if a.Sha1 != nil {
+			root.Checksums = append(root.Checksums, Checksum{Algorithm: "SHA1", ChecksumValue: *a.Sha1})
+		}
+	}
+
+	return fromGraph(root.Name, root, graph.FromArtifactGraph(output))
+}
+
+// FromBuildGraph converts the dependency graph of a build, the document describes the build
+func FromBuildGraph(output *v1.GetBuildDependencyGraphOutput) *Document {
+	root := Package{}
+	if b := output.Build; b != nil {
+		root.Name = ptr.StringValue(b.Name)
+		if id, err := componentid.Parse(ptr.StringValue(b.ComponentId)); err == nil {
+			root.VersionInfo = id.Version
+		}
+		if b.Sha256 != nil {
+			root.Checksums = append(root.Checksums, Checksum{Algorithm: "SHA256", ChecksumValue: *b.Sha256})
+		}
+	}
+
+	name := root.Name
+	if root.VersionInfo != "" {
+		name += "-" + root.VersionInfo
+	}
+
+	return fromGraph(name, root, graph.FromBuildGraph(output))
+}
+
+// AddSummaryLicenses sets the licenses reported by a summary on the packages of the components they apply to
+func (d *Document) AddSummaryLicenses(summary *v1.Summary) {
+	if summary == nil || summary.Artifacts == nil {
+		return
+	}
+
+	for _, artifact := range *summary.Artifacts {
+		if artifact.Licenses == nil {
+			continue
+		}
+
+		for _, l := range *artifact.Licenses {
+			if l.Components == nil {
+				continue
+			}
+
+			for _, componentId := range *l.Components {
+				d.addLicense(componentId, ptr.StringValue(l.Name), ptr.StringValue(l.FullName))
+			}
+		}
+	}
+}
+
+// AddLicenseReportComponents sets the licenses of the license report components on their packages
+func (d *Document) AddLicenseReportComponents(components []v1.LicenseReportComponent) {
+	for _, c := range components {
+		if c.Licenses == nil {
+			continue
+		}
+
+		for _, name := range *c.Licenses {
+			d.addLicense(ptr.StringValue(c.Id), name, "")
+		}
+	}
+}
+
+// addLicense records a license of a component and updates the declared and concluded licenses of its package
+// The declared license is the conjunction of the licenses found by Xray. Xray does not tell whether several licenses
+// are a choice, so the license is only concluded when there is a single one
+func (d *Document) addLicense(componentId string, name string, fullName string) {
+	i, ok := d.packages[componentId]
+	if !ok || name == "" || strings.EqualFold(name, "unknown") {
+		return
+	}
+
+	p := &d.Packages[i]
+	for _, existing := range p.licenses {
+		if existing == name {
+			return
+		}
+	}
+	p.licenses = append(p.licenses, name)
+
+	var ids []string
+	for _, l := range p.licenses {
+		ids = append(ids, d.licenseId(l, fullName))
+	}
+
+	if len(ids) == 1 {
+		p.LicenseDeclared = ids[0]
+		p.LicenseConcluded = ids[0]
+		return
+	}
+
+	p.LicenseDeclared = "(" + strings.Join(ids, " AND ") + ")"
+	p.LicenseConcluded = NoAssertion
+}
+
+// licenseId returns the SPDX id of a license, licenses without an SPDX id are referenced as LicenseRef-name and
+// described in HasExtractedLicensingInfos
+func (d *Document) licenseId(name string, fullName string) string {
+	if id, ok := spdxlicense.Id(name); ok {
+		return id
+	}
+
+	id := "LicenseRef-" + strings.Trim(invalidIdChars.ReplaceAllString(name, "-"), "-")
+	for _, info := range d.HasExtractedLicensingInfos {
+		if info.LicenseId == id {
+			return id
+		}
+	}
+
+	if fullName == "" {
+		fullName = name
+	}
+
+	d.HasExtractedLicensingInfos = append(d.HasExtractedLicensingInfos, ExtractedLicensingInfo{
+		LicenseId:     id,
+		ExtractedText: fullName,
+		Name:          name,
+	})
+	return id
+}
+
+// SetNamespace sets the namespace of the document to a unique URI under base, made of the document name and a random
+// UUID, e.g. https://example.com/spdx/app-1.0.0-2d1c1b3e-5bb0-4d8a-9f5c-3c2f6c0e1a7d
+func (d *Document) SetNamespace(base string) error {
+	if base == "" {
+		return fmt.Errorf("namespace base is required")
+	}
+
+	id, err := uuid.New()
+	if err != nil {
+		return err
+	}
+
+	d.DocumentNamespace = strings.TrimSuffix(base, "/") + "/" + strings.Trim(invalidIdChars.ReplaceAllString(d.Name, "-"), "-") + "-" + id
+	return nil
+}
+
+func (d *Document) checkNamespace() error {
+	if d.DocumentNamespace == "" {
+		return fmt.Errorf("document has no namespace, see SetNamespace")
+	}
+
+	return nil
+}
+
+// WriteJSON writes the document as indented JSON
+func (d *Document) WriteJSON(w io.Writer) error {
+	if err := d.checkNamespace(); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteTagValue writes the document in the tag-value format
+func (d *Document) WriteTagValue(w io.Writer) error {
+	if err := d.checkNamespace(); err != nil {
+		return err
+	}
+
+	tw := &tagWriter{w: w}
+
+	tw.tag("SPDXVersion", d.SPDXVersion)
+	tw.tag("DataLicense", d.DataLicense)
+	tw.tag("SPDXID", d.SPDXID)
+	tw.tag("DocumentName", d.Name)
+	tw.tag("DocumentNamespace", d.DocumentNamespace)
+	for _, creator := range d.CreationInfo.Creators {
+		tw.tag("Creator", creator)
+	}
+	tw.tag("Created", d.CreationInfo.Created)
+
+	for _, p := range d.Packages {
+		tw.line("")
+		tw.line("##### Package: " + p.Name)
+		tw.line("")
+		tw.tag("PackageName", p.Name)
+		tw.tag("SPDXID", p.SPDXID)
+		if p.VersionInfo != "" {
+			tw.tag("PackageVersion", p.VersionInfo)
+		}
+		tw.tag("PackageDownloadLocation", p.DownloadLocation)
+		tw.tag("FilesAnalyzed", fmt.Sprintf("%t", p.FilesAnalyzed))
+		for _, c := range p.Checksums {
+			tw.tag("PackageChecksum", c.Algorithm+": "+c.ChecksumValue)
+		}
+		tw.tag("PackageLicenseConcluded", p.LicenseConcluded)
+		tw.tag("PackageLicenseDeclared", p.LicenseDeclared)
+		tw.tag("PackageCopyrightText", p.CopyrightText)
+		for _, r := range p.ExternalRefs {
+			tw.tag("ExternalRef", r.ReferenceCategory+" "+r.ReferenceType+" "+r.ReferenceLocator)
+		}
+	}
+
+	if len(d.Relationships) > 0 {
+		tw.line("")
+		tw.line("##### Relationships")
+		tw.line("")
+		for _, r := range d.Relationships {
+			tw.tag("Relationship", r.SpdxElementId+" "+r.RelationshipType+" "+r.RelatedSpdxElement)
+		}
+	}
+
+	for _, info := range d.HasExtractedLicensingInfos {
+		tw.line("")
+		tw.line("##### License: " + info.Name)
+		tw.line("")
+		tw.tag("LicenseID", info.LicenseId)
+		tw.tag("ExtractedText", "<text>"+info.ExtractedText+"</text>")
+		if info.Name != "" {
+			tw.tag("LicenseName", info.Name)
+		}
+	}
+
+	return tw.err
+}
+
+// tagWriter keeps the first write error so that WriteTagValue checks it once
+type tagWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (tw *tagWriter) line(s string) {
+	if tw.err == nil {
+		_, tw.err = io.WriteString(tw.w, s+"\n")
+	}
+}
+
+func (tw *tagWriter) tag(name string, value string) {
+	tw.line(name + ": " + value)
+}
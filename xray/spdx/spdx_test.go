@@ -0,0 +1,142 @@
+package spdx
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/internal/golden"
+	"github.com/xero-oss/go-xray/xray/v1"
+)
+
+// newTestDocument converts the test build graph and summary with a fixed creation time and namespace
+func newTestDocument(t *testing.T) *Document {
+	var graph v1.GetBuildDependencyGraphOutput
+	golden.ReadJSON(t, "testdata/build_graph.json", &graph)
+
+	var summary v1.Summary
+	golden.ReadJSON(t, "testdata/summary.json", &summary)
+
+	d := FromBuildGraph(&graph)
+	d.AddSummaryLicenses(&summary)
+	d.AddLicenseReportComponents([]v1.LicenseReportComponent{
+		{Id: v1.String("gav://commons-io:commons-io:2.2"), Licenses: &[]string{"Apache-2.0", "MIT"}},
+	})
+	d.CreationInfo.Created = "2019-04-02T11:28:46Z"
+	d.DocumentNamespace = "https://example.com/spdx/app-42"
+	return d
+}
+
+func TestWriteJSON_golden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestDocument(t).WriteJSON(&buf); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/build.spdx.json", buf.Bytes())
+}
+
+func TestWriteTagValue_golden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newTestDocument(t).WriteTagValue(&buf); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	golden.Check(t, "testdata/build.spdx", buf.Bytes())
+}
+
+func TestLicenses(t *testing.T) {
+	d := newTestDocument(t)
+
+	cases := []struct {
+		name      string
+		declared  string
+		concluded string
+	}{
+		{"app", NoAssertion, NoAssertion},
+		{"org.apache.struts:struts2-core", "Apache-2.0", "Apache-2.0"},
+		{"commons-io:commons-io", "(Apache-2.0 AND MIT)", NoAssertion},
+		{"org.freemarker:freemarker", "LicenseRef-Freemarker-License", "LicenseRef-Freemarker-License"},
+	}
+
+	if len(d.Packages) != len(cases) {
+		t.Fatalf("Expected %d packages but got: %d", len(cases), len(d.Packages))
+	}
+
+	for i, c := range cases {
+		p := d.Packages[i]
+		if p.Name != c.name || p.LicenseDeclared != c.declared || p.LicenseConcluded != c.concluded {
+			t.Errorf("Expected package %s declared %s concluded %s but got: %s declared %s concluded %s",
+				c.name, c.declared, c.concluded, p.Name, p.LicenseDeclared, p.LicenseConcluded)
+		}
+	}
+
+	if len(d.HasExtractedLicensingInfos) != 1 || d.HasExtractedLicensingInfos[0].ExtractedText != "The FreeMarker License" {
+		t.Errorf("Unexpected extracted licensing infos: %+v", d.HasExtractedLicensingInfos)
+	}
+}
+
+func TestFromArtifactGraph(t *testing.T) {
+	d := FromArtifactGraph(&v1.GetArtifactDependencyGraphOutput{
+		Artifact: &v1.Artifact{
+			Name:        v1.String("app-1.0.0.tgz"),
+			ComponentId: v1.String("npm://app:1.0.0"),
+			Sha1:        v1.String("da39a3ee5e6b4b0d3255bfef95601890afd80709"),
+		},
+		Components: &[]v1.GraphComponent{
+			{ComponentName: v1.String("lodash"), ComponentId: v1.String("npm://lodash:4.17.4"), Version: v1.String("4.17.4")},
+		},
+	})
+
+	if d.Name != "app-1.0.0.tgz" || len(d.Packages) != 2 {
+		t.Fatalf("Unexpected document: %+v", d)
+	}
+
+	expected := []Relationship{
+		{SpdxElementId: DocumentId, RelationshipType: RelationshipDescribes, RelatedSpdxElement: "SPDXRef-Package-npm-app-1.0.0"},
+		{SpdxElementId: "SPDXRef-Package-npm-app-1.0.0", RelationshipType: RelationshipDependsOn, RelatedSpdxElement: "SPDXRef-Package-npm-lodash-4.17.4"},
+	}
+	for i, r := range expected {
+		if d.Relationships[i] != r {
+			t.Errorf("Expected relationship %+v but got: %+v", r, d.Relationships[i])
+		}
+	}
+
+	if d.Packages[0].Checksums[0].Algorithm != "SHA1" {
+		t.Errorf("Expected a SHA1 checksum but got: %+v", d.Packages[0].Checksums)
+	}
+}
+
+func TestSetNamespace(t *testing.T) {
+	d := newTestDocument(t)
+	if err := d.SetNamespace("https://example.com/spdx/"); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+	first := d.DocumentNamespace
+
+	if err := d.SetNamespace("https://example.com/spdx"); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if !strings.HasPrefix(first, "https://example.com/spdx/app-42-") || len(first) != len("https://example.com/spdx/app-42-")+36 {
+		t.Errorf("Expected the namespace to be the base, the name and a UUID but got: %s", first)
+	}
+
+	if first == d.DocumentNamespace {
+		t.Errorf("Expected a different namespace for every call but got %s twice", first)
+	}
+
+	if err := d.SetNamespace(""); err == nil {
+		t.Errorf("Expected an error for an empty base but got nil")
+	}
+}
+
+func TestWriteJSON_noNamespace(t *testing.T) {
+	d := newTestDocument(t)
+	d.DocumentNamespace = ""
+
+	if err := d.WriteJSON(ioutil.Discard); err == nil {
+		t.Errorf("Expected an error for a document without namespace but got nil")
+	}
+}
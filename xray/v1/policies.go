@@ -8,6 +8,12 @@ import (
 
 type PoliciesService Service
 
+// Policy types supported by the v1 API
+const (
+	PolicyTypeSecurity = "security"
+	PolicyTypeLicense  = "license"
+)
+
 type PolicyCVSSRange struct {
 	To   *int `json:"to,omitempty"`
 	From *int `json:"from,omitempty"`
@@ -65,10 +65,10 @@ type GetLicenseReportComponentsInput struct {
 func (s *GetLicenseReportComponentsInput) toQueryString() (string, error) {
 	var queryString string
 	if s.Compliance != nil {
-		queryString = fmt.Sprintf("compliance=%s", s.Compliance)
+		queryString = fmt.Sprintf("compliance=%s", *s.Compliance)
 	} else {
 		if s.License != nil {
-			queryString = fmt.Sprintf("license=%s", s.License)
+			queryString = fmt.Sprintf("license=%s", *s.License)
 		}
 	}
 	// An initial filter is required
@@ -77,13 +77,13 @@ func (s *GetLicenseReportComponentsInput) toQueryString() (string, error) {
 	}
 
 	if s.NumberOfRows != nil {
-		queryString = fmt.Sprintf("%s&num_of_rows=%s", queryString, s.NumberOfRows)
+		queryString = fmt.Sprintf("%s&num_of_rows=%d", queryString, *s.NumberOfRows)
 	}
 	if s.OrderBy != nil {
-		queryString = fmt.Sprintf("%s&order_by=%s", queryString, s.OrderBy)
+		queryString = fmt.Sprintf("%s&order_by=%s", queryString, *s.OrderBy)
 	}
 	if s.PageNumber != nil {
-		queryString = fmt.Sprintf("%s&page_num=%s", queryString, s.PageNumber)
+		queryString = fmt.Sprintf("%s&page_num=%d", queryString, *s.PageNumber)
 	}
 
 	return queryString, nil
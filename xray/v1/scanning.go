@@ -104,7 +104,7 @@ type BuildScanLicense struct {
 type ScanBuildOutput struct {
 	Summary  *BuildScanSummary   `json:"summary,omitempty"`
 	Alerts   *[]BuileScanAlert   `json:"alerts,omitempty"`
-	Licenses *[]BuildScanLicense `json:"path,omitempty"`
+	Licenses *[]BuildScanLicense `json:"licenses,omitempty"`
 }
 
 // Description:  Invokes scanning of an artifact
@@ -16,14 +16,22 @@ type SummaryArtifactGeneral struct {
 	Sha256      *string `json:"sha256,omitempty"`
 }
 
+type SummaryArtifactIssueCve struct {
+	Cve    *string `json:"cve,omitempty"`
+	CvssV2 *string `json:"cvss_v2,omitempty"`
+	CvssV3 *string `json:"cvss_v3,omitempty"`
+}
+
 type SummaryArtifactIssue struct {
-	Created     *string   `json:"created,omitempty"`
-	Description *string   `json:"description,omitempty"`
-	ImpactPath  *[]string `json:"impact_path,omitempty"`
-	IssueType   *string   `json:"issue_type,omitempty"`
-	Provider    *string   `json:"provider,omitempty"`
-	Severity    *string   `json:"severity,omitempty"`
-	Summary     *string   `json:"summary,omitempty"`
+	IssueId     *string                    `json:"issue_id,omitempty"`
+	Created     *string                    `json:"created,omitempty"`
+	Cves        *[]SummaryArtifactIssueCve `json:"cves,omitempty"`
+	Description *string                    `json:"description,omitempty"`
+	ImpactPath  *[]string                  `json:"impact_path,omitempty"`
+	IssueType   *string                    `json:"issue_type,omitempty"`
+	Provider    *string                    `json:"provider,omitempty"`
+	Severity    *string                    `json:"severity,omitempty"`
+	Summary     *string                    `json:"summary,omitempty"`
 }
 
 type SummaryArtifactLicense struct {
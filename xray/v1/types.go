@@ -23,4 +23,5 @@ type V1 struct {
 	System         *SystemService
 	Users          *UsersService
 	Violations     *ViolationsService
+	Webhooks       *WebhooksService
 }
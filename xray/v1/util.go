@@ -21,6 +21,7 @@ func NewV1(client *client.Client) *V1 {
 	v.System = (*SystemService)(&v.common)
 	v.Users = (*UsersService)(&v.common)
 	v.Violations = (*ViolationsService)(&v.common)
+	v.Webhooks = (*WebhooksService)(&v.common)
 
 	return v
 }
@@ -10,15 +10,16 @@ type ViolationsService Service
 type GetViolationsFilters struct {
 	NameContains    *string `json:"name_contains,omitempty"`
 	ViolationType   *string `json:"violation_type,omitempty"`
-	WatchName       *string `json:"name_contains,omitempty"`
+	WatchName       *string `json:"watch_name,omitempty"`
 	MinimumSeverity *string `json:"min_severity,omitempty"`
 	CreatedFrom     *string `json:"created_from,omitempty"`
 }
 
 type GetViolationsPagination struct {
-	OrderBy *string `json:"order_by,omitempty"`
-	Limit   *int    `json:"limit,omitempty"`
-	Offset  *string `json:"offset,omitempty"`
+	OrderBy   *string `json:"order_by,omitempty"`
+	Direction *string `json:"direction,omitempty"`
+	Limit     *int    `json:"limit,omitempty"`
+	Offset    *string `json:"offset,omitempty"`
 }
 
 type GetViolationsInput struct {
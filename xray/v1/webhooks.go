@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type WebhooksService Service
+
+type Webhook struct {
+	Name        *string            `json:"name,omitempty"`
+	Url         *string            `json:"url,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	UseProxy    *bool              `json:"use_proxy,omitempty"`
+	User        *string            `json:"user_name,omitempty"`
+	Password    *string            `json:"password,omitempty"`
+	Headers     *map[string]string `json:"headers,omitempty"`
+}
+
+type TestWebhookOutput struct {
+	Info *string `json:"info,omitempty"`
+}
+
+// Description: Gets a list of all webhooks in the system
+// Security:  Requires an admin user
+// Usage: client.V1.Webhooks.ListWebhooks(ctx)
+func (s *WebhooksService) ListWebhooks(ctx context.Context) (*[]Webhook, *http.Response, error) {
+	req, err := s.client.NewRequest("GET", "/api/v1/webhooks", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	webhooks := new([]Webhook)
+	resp, err := s.client.Do(ctx, req, &webhooks)
+	return webhooks, resp, err
+}
+
+// Description: Gets a named webhook
+// Security:  Requires an admin user
+// Usage: client.V1.Webhooks.GetWebhook(ctx, "name")
+func (s *WebhooksService) GetWebhook(ctx context.Context, name string) (*Webhook, *http.Response, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s", name)
+	req, err := s.client.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	webhook := new(Webhook)
+	resp, err := s.client.Do(ctx, req, &webhook)
+	return webhook, resp, err
+}
+
+// Description: Creates a new webhook
+// Security:  Requires an admin user
+// Usage: client.V1.Webhooks.CreateWebhook(ctx, webhook)
+func (s *WebhooksService) CreateWebhook(ctx context.Context, webhook *Webhook) (*http.Response, error) {
+	req, err := s.client.NewJSONEncodedRequest("POST", "/api/v1/webhooks", webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Description: Updates a webhook.
+// Security:  Requires an admin user
+// Usage: client.V1.Webhooks.UpdateWebhook(ctx, "name", webhook)
+func (s *WebhooksService) UpdateWebhook(ctx context.Context, name string, webhook *Webhook) (*http.Response, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s", name)
+	req, err := s.client.NewJSONEncodedRequest("PUT", path, webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Description: Deletes a webhook
+// Security:  Requires an admin user
+// Usage: client.V1.Webhooks.DeleteWebhook(ctx, "name")
+func (s *WebhooksService) DeleteWebhook(ctx context.Context, name string) (*http.Response, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s", name)
+	req, err := s.client.NewRequest("DELETE", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Description: Sends a test event to the webhook URL
+// Security:  Requires an admin user
+// Usage: client.V1.Webhooks.TestWebhook(ctx, "name")
+func (s *WebhooksService) TestWebhook(ctx context.Context, name string) (*TestWebhookOutput, *http.Response, error) {
+	path := fmt.Sprintf("/api/v1/webhooks/%s/test", name)
+	req, err := s.client.NewRequest("POST", path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	output := new(TestWebhookOutput)
+	resp, err := s.client.Do(ctx, req, &output)
+	return output, resp, err
+}
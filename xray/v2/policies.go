@@ -0,0 +1,167 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type PoliciesService Service
+
+// Policy types supported by the v2 API
+const (
+	PolicyTypeSecurity        = "security"
+	PolicyTypeLicense         = "license"
+	PolicyTypeOperationalRisk = "operational_risk"
+)
+
+type PolicyCVSSRange struct {
+	To   *float64 `json:"to,omitempty"`
+	From *float64 `json:"from,omitempty"`
+}
+
+type PolicyExposures struct {
+	MinimumSeverity *string `json:"min_severity,omitempty"`
+	Secrets         *bool   `json:"secrets,omitempty"`
+	Applications    *bool   `json:"applications,omitempty"`
+	Services        *bool   `json:"services,omitempty"`
+	Iac             *bool   `json:"iac,omitempty"`
+}
+
+type PolicyOperationalRiskCustom struct {
+	UseAndCondition               *bool   `json:"use_and_condition,omitempty"`
+	IsEOL                         *bool   `json:"is_eol,omitempty"`
+	ReleaseDateGreaterThanMonths  *int    `json:"release_date_greater_than_months,omitempty"`
+	NewerVersionsGreaterThan      *int    `json:"newer_versions_greater_than,omitempty"`
+	ReleaseCadencePerYearLessThan *int    `json:"release_cadence_per_year_less_than,omitempty"`
+	CommitsLessThan               *int    `json:"commits_less_than,omitempty"`
+	CommittersLessThan            *int    `json:"committers_less_than,omitempty"`
+	Risk                          *string `json:"risk,omitempty"`
+}
+
+type PolicyRuleCriteria struct {
+	// Security Criteria
+	MinimumSeverity     *string          `json:"min_severity,omitempty"`
+	CVSSRange           *PolicyCVSSRange `json:"cvss_range,omitempty"`
+	FixVersionDependant *bool            `json:"fix_version_dependant,omitempty"`
+	ApplicableCVEsOnly  *bool            `json:"applicable_cves_only,omitempty"`
+	VulnerabilityIds    *[]string        `json:"vulnerability_ids,omitempty"`
+	Exposures           *PolicyExposures `json:"exposures,omitempty"`
+	MaliciousPackage    *bool            `json:"malicious_package,omitempty"`
+
+	// License Criteria
+	AllowUnknown           *bool     `json:"allow_unknown,omitempty"`
+	BannedLicenses         *[]string `json:"banned_licenses,omitempty"`
+	AllowedLicenses        *[]string `json:"allowed_licenses,omitempty"`
+	MultiLicensePermissive *bool     `json:"multi_license_permissive,omitempty"`
+
+	// Operational Risk Criteria
+	OperationalRiskMinimumRisk *string                      `json:"op_risk_min_risk,omitempty"`
+	OperationalRiskCustom      *PolicyOperationalRiskCustom `json:"op_risk_custom,omitempty"`
+}
+
+type BlockDownloadSettings struct {
+	Unscanned *bool `json:"unscanned,omitempty"`
+	Active    *bool `json:"active,omitempty"`
+}
+
+type PolicyRuleActions struct {
+	Mails                          *[]string              `json:"mails,omitempty"`
+	Webhooks                       *[]string              `json:"webhooks,omitempty"`
+	FailBuild                      *bool                  `json:"fail_build,omitempty"`
+	BlockDownload                  *BlockDownloadSettings `json:"block_download,omitempty"`
+	BlockReleaseBundleDistribution *bool                  `json:"block_release_bundle_distribution,omitempty"`
+	NotifyWatchRecipients          *bool                  `json:"notify_watch_recipients,omitempty"`
+	NotifyDeployer                 *bool                  `json:"notify_deployer,omitempty"`
+	CreateTicketEnabled            *bool                  `json:"create_ticket_enabled,omitempty"`
+	BuildFailureGracePeriodInDays  *int                   `json:"build_failure_grace_period_in_days,omitempty"`
+	CustomSeverity                 *string                `json:"custom_severity,omitempty"`
+}
+
+type PolicyRule struct {
+	Name     *string             `json:"name,omitempty"`
+	Priority *int                `json:"priority,omitempty"`
+	Criteria *PolicyRuleCriteria `json:"criteria,omitempty"`
+	Actions  *PolicyRuleActions  `json:"actions,omitempty"`
+}
+
+type Policy struct {
+	Name        *string       `json:"name,omitempty"`
+	Type        *string       `json:"type,omitempty"`
+	Author      *string       `json:"author,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Rules       *[]PolicyRule `json:"rules,omitempty"`
+	Created     *string       `json:"created,omitempty"`
+	Modified    *string       `json:"modified,omitempty"`
+}
+
+// Description:  Gets a list of all policies in the system
+// Security:  Requires a user with "View Watches" permission
+// Usage: client.V2.Policies.ListPolicies(ctx)
+func (s *PoliciesService) ListPolicies(ctx context.Context) (*[]Policy, *http.Response, error) {
+	req, err := s.client.NewRequest("GET", "/api/v2/policies", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	policies := new([]Policy)
+	resp, err := s.client.Do(ctx, req, &policies)
+	return policies, resp, err
+}
+
+// Description:  Gets a specific policy
+// Security:  Requires a user with "View Watches" permission
+// Usage: client.V2.Policies.GetPolicy(ctx, "name")
+func (s *PoliciesService) GetPolicy(ctx context.Context, name string) (*Policy, *http.Response, error) {
+	path := fmt.Sprintf("/api/v2/policies/%s", name)
+	req, err := s.client.NewRequest("GET", path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	policy := new(Policy)
+	resp, err := s.client.Do(ctx, req, &policy)
+	return policy, resp, err
+}
+
+// Description: Creates a new policy
+// Security:  Requires a user with "Manage Policies" permission
+// Usage: client.V2.Policies.CreatePolicy(ctx, policy)
+func (s *PoliciesService) CreatePolicy(ctx context.Context, policy *Policy) (*http.Response, error) {
+	req, err := s.client.NewJSONEncodedRequest("POST", "/api/v2/policies", policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Description: Updates a policy.
+// Security:  Requires a user with "Manage Policies" permission
+// Usage: client.V2.Policies.UpdatePolicy(ctx, "name", policy)
+func (s *PoliciesService) UpdatePolicy(ctx context.Context, name string, policy *Policy) (*http.Response, error) {
+	path := fmt.Sprintf("/api/v2/policies/%s", name)
+	req, err := s.client.NewJSONEncodedRequest("PUT", path, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Description: Deletes a Policy
+// Security:  Requires a user with "Manage Policies" permission
+// Usage: client.V2.Policies.DeletePolicy(ctx, "name")
+func (s *PoliciesService) DeletePolicy(ctx context.Context, name string) (*http.Response, error) {
+	path := fmt.Sprintf("/api/v2/policies/%s", name)
+	req, err := s.client.NewRequest("DELETE", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
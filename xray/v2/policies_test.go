@@ -0,0 +1,104 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/atlassian/go-artifactory/v2/artifactory/client"
+)
+
+func TestPoliciesServiceCreatePolicy_operationalRisk(t *testing.T) {
+	var body map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v2/policies" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		data, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("Got the following error: %s", err.Error())
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c, _ := client.NewClient(server.URL, nil)
+	v := NewV2(c)
+
+	grace := 3
+	notify := true
+	policy := Policy{
+		Name: String("op-risk"),
+		Type: String(PolicyTypeOperationalRisk),
+		Rules: &[]PolicyRule{
+			{
+				Name: String("eol"),
+				Criteria: &PolicyRuleCriteria{
+					OperationalRiskMinimumRisk: String("Medium"),
+				},
+				Actions: &PolicyRuleActions{
+					NotifyWatchRecipients:         &notify,
+					BuildFailureGracePeriodInDays: &grace,
+				},
+			},
+		},
+	}
+
+	if _, err := v.Policies.CreatePolicy(context.Background(), &policy); err != nil {
+		t.Errorf("Got the following error: %s", err.Error())
+	}
+
+	rule := body["rules"].([]interface{})[0].(map[string]interface{})
+	criteria := rule["criteria"].(map[string]interface{})
+	actions := rule["actions"].(map[string]interface{})
+
+	if criteria["op_risk_min_risk"] != "Medium" {
+		t.Errorf("Expected op_risk_min_risk to be 'Medium' but got: %v", criteria["op_risk_min_risk"])
+	}
+
+	if actions["notify_watch_recipients"] != true {
+		t.Errorf("Expected notify_watch_recipients to be true but got: %v", actions["notify_watch_recipients"])
+	}
+
+	if actions["build_failure_grace_period_in_days"] != float64(3) {
+		t.Errorf("Expected build_failure_grace_period_in_days to be 3 but got: %v", actions["build_failure_grace_period_in_days"])
+	}
+}
+
+func TestPolicyRuleCriteriaUnmarshalJSON_securityCriteria(t *testing.T) {
+	blob := `{
+		"min_severity": "High",
+		"fix_version_dependant": true,
+		"vulnerability_ids": ["CVE-2021-44228"],
+		"exposures": {"min_severity": "critical", "secrets": true},
+		"malicious_package": true,
+		"cvss_range": {"from": 7.5, "to": 10}
+	}`
+
+	var criteria PolicyRuleCriteria
+	if err := json.Unmarshal([]byte(blob), &criteria); err != nil {
+		t.Errorf("Got the following error: %s", err.Error())
+	}
+
+	if !*criteria.FixVersionDependant {
+		t.Errorf("Expected FixVersionDependant to be true but got false")
+	}
+
+	if (*criteria.VulnerabilityIds)[0] != "CVE-2021-44228" {
+		t.Errorf("Expected vulnerability id to be 'CVE-2021-44228' but got: %s", (*criteria.VulnerabilityIds)[0])
+	}
+
+	if !*criteria.Exposures.Secrets {
+		t.Errorf("Expected exposures secrets to be true but got false")
+	}
+
+	if *criteria.CVSSRange.From != 7.5 {
+		t.Errorf("Expected CVSS range from to be 7.5 but got: %v", *criteria.CVSSRange.From)
+	}
+}
@@ -10,5 +10,6 @@ type V2 struct {
 	common Service
 
 	// Services used for talking to different parts of the Xray API.
-	Watches *WatchesService
+	Policies *PoliciesService
+	Watches  *WatchesService
 }
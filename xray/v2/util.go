@@ -6,7 +6,12 @@ func NewV2(client *client.Client) *V2 {
 	v := &V2{}
 	v.common.client = client
 
+	v.Policies = (*PoliciesService)(&v.common)
 	v.Watches = (*WatchesService)(&v.common)
 
 	return v
 }
+
+// String is a helper routine that allocates a new string value
+// to store v and returns a pointer to it.
+func String(v string) *string { return &v }
@@ -0,0 +1,285 @@
+package v2
+
+import (
+	"fmt"
+)
+
+// Resource types supported by a watch
+const (
+	WatchResourceTypeRepository        = "repository"
+	WatchResourceTypeAllRepos          = "all-repos"
+	WatchResourceTypeBuild             = "build"
+	WatchResourceTypeAllBuilds         = "all-builds"
+	WatchResourceTypeProject           = "project"
+	WatchResourceTypeAllProjects       = "all-projects"
+	WatchResourceTypeReleaseBundle     = "releaseBundle"
+	WatchResourceTypeAllReleaseBundles = "all-releaseBundles"
+)
+
+// Filter types supported by the watch resources
+const (
+	WatchFilterTypeRegex           = "regex"
+	WatchFilterTypePackageType     = "package-type"
+	WatchFilterTypeMimeType        = "mime-type"
+	WatchFilterTypeProperty        = "property"
+	WatchFilterTypePathRegex       = "path-regex"
+	WatchFilterTypePathAntPatterns = "path-ant-patterns"
+	WatchFilterTypeAntPatterns     = "ant-patterns"
+)
+
+var repositoryFilterTypes = []string{
+	WatchFilterTypeRegex,
+	WatchFilterTypePackageType,
+	WatchFilterTypeMimeType,
+	WatchFilterTypeProperty,
+	WatchFilterTypePathRegex,
+	WatchFilterTypePathAntPatterns,
+}
+
+// watchResourceRules describes, for each resource type, whether a name is required and which filters are legal
+var watchResourceRules = map[string]struct {
+	named   bool
+	filters []string
+}{
+	WatchResourceTypeRepository:        {named: true, filters: repositoryFilterTypes},
+	WatchResourceTypeAllRepos:          {named: false, filters: repositoryFilterTypes},
+	WatchResourceTypeBuild:             {named: true},
+	WatchResourceTypeAllBuilds:         {named: false, filters: []string{WatchFilterTypeAntPatterns}},
+	WatchResourceTypeProject:           {named: true},
+	WatchResourceTypeAllProjects:       {named: false, filters: []string{WatchFilterTypeAntPatterns}},
+	WatchResourceTypeReleaseBundle:     {named: true},
+	WatchResourceTypeAllReleaseBundles: {named: false, filters: []string{WatchFilterTypeAntPatterns}},
+}
+
+// NewRepositoryResource creates a watch resource for a single repository managed by the given binary manager
+func NewRepositoryResource(binMgrId string, name string, filters ...WatchFilter) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeRepository, binMgrId, name, filters)
+}
+
+// NewAllReposResource creates a watch resource for every repository managed by the given binary manager
+func NewAllReposResource(binMgrId string, filters ...WatchFilter) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeAllRepos, binMgrId, "", filters)
+}
+
+// NewBuildResource creates a watch resource for a single build
+func NewBuildResource(binMgrId string, name string) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeBuild, binMgrId, name, nil)
+}
+
+// NewAllBuildsResource creates a watch resource for all builds matching the include patterns and none of the exclude patterns.
+// Empty patterns select every build
+func NewAllBuildsResource(binMgrId string, includePatterns []string, excludePatterns []string) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeAllBuilds, binMgrId, "", antPatternsFilters(includePatterns, excludePatterns))
+}
+
+// NewProjectResource creates a watch resource for a single project
+func NewProjectResource(binMgrId string, name string) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeProject, binMgrId, name, nil)
+}
+
+// NewAllProjectsResource creates a watch resource for all projects matching the include patterns and none of the exclude patterns.
+// Empty patterns select every project
+func NewAllProjectsResource(binMgrId string, includePatterns []string, excludePatterns []string) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeAllProjects, binMgrId, "", antPatternsFilters(includePatterns, excludePatterns))
+}
+
+// NewReleaseBundleResource creates a watch resource for a single release bundle
+func NewReleaseBundleResource(binMgrId string, name string) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeReleaseBundle, binMgrId, name, nil)
+}
+
+// NewAllReleaseBundlesResource creates a watch resource for all release bundles matching the include patterns and none of the
+// exclude patterns. Empty patterns select every release bundle
+func NewAllReleaseBundlesResource(binMgrId string, includePatterns []string, excludePatterns []string) WatchProjectResource {
+	return newWatchProjectResource(WatchResourceTypeAllReleaseBundles, binMgrId, "", antPatternsFilters(includePatterns, excludePatterns))
+}
+
+func newWatchProjectResource(resourceType string, binMgrId string, name string, filters []WatchFilter) WatchProjectResource {
+	r := WatchProjectResource{
+		Type:            String(resourceType),
+		BinaryManagerId: String(binMgrId),
+	}
+
+	if name != "" {
+		r.Name = String(name)
+	}
+
+	if len(filters) > 0 {
+		r.Filters = &filters
+	}
+
+	return r
+}
+
+func antPatternsFilters(includePatterns []string, excludePatterns []string) []WatchFilter {
+	if len(includePatterns) == 0 && len(excludePatterns) == 0 {
+		return nil
+	}
+
+	return []WatchFilter{NewAntPatternsFilter(includePatterns, excludePatterns)}
+}
+
+// NewWatchProjectResources wraps a list of resources so they can be set on Watch.ProjectResources
+func NewWatchProjectResources(resources ...WatchProjectResource) *WatchProjectResources {
+	return &WatchProjectResources{Resources: &resources}
+}
+
+// NewRegexFilter creates a filter matching artifact names against a regular expression
+func NewRegexFilter(regex string) WatchFilter {
+	return newStringFilter(WatchFilterTypeRegex, regex)
+}
+
+// NewPackageTypeFilter creates a filter matching artifacts of the given package type
+func NewPackageTypeFilter(packageType string) WatchFilter {
+	return newStringFilter(WatchFilterTypePackageType, packageType)
+}
+
+// NewMimeTypeFilter creates a filter matching artifacts of the given mime type
+func NewMimeTypeFilter(mimeType string) WatchFilter {
+	return newStringFilter(WatchFilterTypeMimeType, mimeType)
+}
+
+// NewPathRegexFilter creates a filter matching artifact paths against a regular expression
+func NewPathRegexFilter(regex string) WatchFilter {
+	return newStringFilter(WatchFilterTypePathRegex, regex)
+}
+
+// NewPropertyFilter creates a filter matching artifacts with the given property
+func NewPropertyFilter(key string, value string) WatchFilter {
+	return WatchFilter{
+		Type: String(WatchFilterTypeProperty),
+		Value: &WatchFilterValueWrapper{
+			WatchFilterValue: WatchFilterValue{
+				Key:   String(key),
+				Value: String(value),
+			},
+			IsPropertyFilter: true,
+		},
+	}
+}
+
+// NewAntPatternsFilter creates a filter matching build, project or release bundle names against ant patterns
+func NewAntPatternsFilter(includePatterns []string, excludePatterns []string) WatchFilter {
+	return newAntPatternsFilter(WatchFilterTypeAntPatterns, includePatterns, excludePatterns)
+}
+
+// NewPathAntPatternsFilter creates a filter matching artifact paths against ant patterns
+func NewPathAntPatternsFilter(includePatterns []string, excludePatterns []string) WatchFilter {
+	return newAntPatternsFilter(WatchFilterTypePathAntPatterns, includePatterns, excludePatterns)
+}
+
+func newStringFilter(filterType string, value string) WatchFilter {
+	return WatchFilter{
+		Type: String(filterType),
+		Value: &WatchFilterValueWrapper{
+			WatchFilterValue: WatchFilterValue{
+				Value: String(value),
+			},
+		},
+	}
+}
+
+func newAntPatternsFilter(filterType string, includePatterns []string, excludePatterns []string) WatchFilter {
+	patterns := &WatchFilterAntPatterns{}
+	if includePatterns != nil {
+		patterns.IncludePatterns = &includePatterns
+	}
+	if excludePatterns != nil {
+		patterns.ExcludePatterns = &excludePatterns
+	}
+
+	return WatchFilter{
+		Type:  String(filterType),
+		Value: &WatchFilterValueWrapper{AntPatterns: patterns},
+	}
+}
+
+// Validate checks that every resource of the watch has a known type and only uses filters that are legal for that type
+// It returns the first problem found
+func (w *Watch) Validate() error {
+	if w == nil || w.ProjectResources == nil || w.ProjectResources.Resources == nil {
+		return nil
+	}
+
+	for i, r := range *w.ProjectResources.Resources {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("invalid watch resource %d: %s", i, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the resource has a known type, a name when the type requires one, and only legal filters
+// It returns the first problem found
+func (r *WatchProjectResource) Validate() error {
+	if r.Type == nil {
+		return fmt.Errorf("resource type is required")
+	}
+
+	rules, ok := watchResourceRules[*r.Type]
+	if !ok {
+		return fmt.Errorf("unknown resource type %q", *r.Type)
+	}
+
+	hasName := r.Name != nil && *r.Name != ""
+	if rules.named && !hasName {
+		return fmt.Errorf("resource type %q requires a name", *r.Type)
+	}
+	if !rules.named && hasName {
+		return fmt.Errorf("resource type %q does not accept a name", *r.Type)
+	}
+
+	if r.Filters == nil {
+		return nil
+	}
+
+	for _, f := range *r.Filters {
+		if f.Type == nil {
+			return fmt.Errorf("filter type is required")
+		}
+
+		if !containsString(rules.filters, *f.Type) {
+			return fmt.Errorf("filter type %q is not allowed for resource type %q", *f.Type, *r.Type)
+		}
+
+		if err := f.validateValue(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *WatchFilter) validateValue() error {
+	if f.Value == nil {
+		return fmt.Errorf("filter %q requires a value", *f.Type)
+	}
+
+	switch *f.Type {
+	case WatchFilterTypeAntPatterns, WatchFilterTypePathAntPatterns:
+		if f.Value.AntPatterns == nil {
+			return fmt.Errorf("filter %q requires include or exclude patterns", *f.Type)
+		}
+	case WatchFilterTypeProperty:
+		if !f.Value.IsPropertyFilter || f.Value.Key == nil {
+			return fmt.Errorf("filter %q requires a key and value", *f.Type)
+		}
+	default:
+		if f.Value.IsPropertyFilter || f.Value.AntPatterns != nil || f.Value.Value == nil {
+			return fmt.Errorf("filter %q requires a string value", *f.Type)
+		}
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package v2
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWatchResourceConstructors_valid(t *testing.T) {
+	watch := Watch{
+		ProjectResources: NewWatchProjectResources(
+			NewRepositoryResource("default", "libs-release", NewPackageTypeFilter("npm"), NewPropertyFilter("team", "core")),
+			NewAllReposResource("default", NewPathAntPatternsFilter([]string{"**/*.jar"}, nil)),
+			NewBuildResource("default", "my-build"),
+			NewAllBuildsResource("default", []string{"release-*"}, []string{"release-test"}),
+			NewProjectResource("default", "my-project"),
+			NewAllProjectsResource("default", nil, nil),
+			NewReleaseBundleResource("default", "my-bundle"),
+			NewAllReleaseBundlesResource("default", []string{"*"}, nil),
+		),
+	}
+
+	if err := watch.Validate(); err != nil {
+		t.Errorf("Expected watch to be valid but got: %s", err.Error())
+	}
+}
+
+func TestWatchResourceConstructors_allBuildsJSON(t *testing.T) {
+	resource := NewAllBuildsResource("default", []string{"release-*"}, nil)
+
+	data, err := json.Marshal(resource)
+	if err != nil {
+		t.Errorf("Got the following error: %s", err.Error())
+	}
+
+	expected := `{"type":"all-builds","bin_mgr_id":"default","filters":[{"type":"ant-patterns","value":{"IncludePatterns":["release-*"]}}]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s but got: %s", expected, string(data))
+	}
+}
+
+func TestWatchProjectResourceValidate_invalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		resource WatchProjectResource
+		message  string
+	}{
+		{
+			name:     "unknown type",
+			resource: WatchProjectResource{Type: String("artifact")},
+			message:  "unknown resource type",
+		},
+		{
+			name:     "missing name",
+			resource: WatchProjectResource{Type: String(WatchResourceTypeBuild)},
+			message:  "requires a name",
+		},
+		{
+			name:     "unexpected name",
+			resource: WatchProjectResource{Type: String(WatchResourceTypeAllRepos), Name: String("libs-release")},
+			message:  "does not accept a name",
+		},
+		{
+			name: "repository filter on build",
+			resource: WatchProjectResource{
+				Type:            String(WatchResourceTypeAllBuilds),
+				BinaryManagerId: String("default"),
+				Filters:         &[]WatchFilter{NewPackageTypeFilter("npm")},
+			},
+			message: "not allowed",
+		},
+		{
+			name:     "ant patterns on repository",
+			resource: NewRepositoryResource("default", "libs-release", NewAntPatternsFilter([]string{"*"}, nil)),
+			message:  "not allowed",
+		},
+		{
+			name: "string value for property filter",
+			resource: NewRepositoryResource("default", "libs-release", WatchFilter{
+				Type:  String(WatchFilterTypeProperty),
+				Value: &WatchFilterValueWrapper{WatchFilterValue: WatchFilterValue{Value: String("core")}},
+			}),
+			message: "requires a key and value",
+		},
+	}
+
+	for _, c := range cases {
+		err := c.resource.Validate()
+		if err == nil {
+			t.Errorf("%s: expected an error but got nil", c.name)
+			continue
+		}
+
+		if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: expected error to contain '%s' but got: %s", c.name, c.message, err.Error())
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type WatchesService Service
@@ -20,10 +21,18 @@ type WatchFilterValue struct {
 	Value *string `json:"value,omitempty"`
 }
 
-// WatchFilterValueWrapper is a wrapper around WatchFilterValue which handles the API returning both a string and an object for the watch filter value
+// WatchFilterAntPatterns is the value of an "ant-patterns" or "path-ant-patterns" filter
+type WatchFilterAntPatterns struct {
+	IncludePatterns *[]string `json:"IncludePatterns,omitempty"`
+	ExcludePatterns *[]string `json:"ExcludePatterns,omitempty"`
+}
+
+// WatchFilterValueWrapper is a wrapper around WatchFilterValue which handles the API returning a string, a key/value
+// object or an ant patterns object for the watch filter value
 type WatchFilterValueWrapper struct {
 	WatchFilterValue
-	IsPropertyFilter bool `json:”-”`
+	IsPropertyFilter bool                    `json:"-"`
+	AntPatterns      *WatchFilterAntPatterns `json:"-"`
 }
 
 type WatchFilter struct {
@@ -35,6 +44,7 @@ type WatchProjectResource struct {
 	Type            *string        `json:"type,omitempty"`
 	BinaryManagerId *string        `json:"bin_mgr_id,omitempty"`
 	Name            *string        `json:"name,omitempty"`
+	RepoType        *string        `json:"repo_type,omitempty"`
 	Filters         *[]WatchFilter `json:"filters,omitempty"`
 }
 
@@ -56,8 +66,30 @@ type Watch struct {
 // UnmarshalJSON converts JSON data into a WatchFilterValueWrapper object
 // It returns any errors that occured during the function
 func (wf *WatchFilterValueWrapper) UnmarshalJSON(data []byte) error {
+	// A reused wrapper must not keep the key, patterns or kind of the value it held before
+	*wf = WatchFilterValueWrapper{}
+
 	var v string
 	if err := json.Unmarshal(data, &v); err != nil {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+
+		_, include := fields["IncludePatterns"]
+		_, exclude := fields["ExcludePatterns"]
+		if include || exclude {
+			var p WatchFilterAntPatterns
+
+			if err := json.Unmarshal(data, &p); err != nil {
+				return err
+			}
+
+			wf.AntPatterns = &p
+
+			return nil
+		}
+
 		var v WatchFilterValue
 
 		if err := json.Unmarshal(data, &v); err != nil {
@@ -71,7 +103,6 @@ func (wf *WatchFilterValueWrapper) UnmarshalJSON(data []byte) error {
 	}
 
 	wf.Value = &v
-	wf.IsPropertyFilter = false
 
 	return nil
 }
@@ -79,6 +110,10 @@ func (wf *WatchFilterValueWrapper) UnmarshalJSON(data []byte) error {
 // MarshalJSON coverts the WatchFilterValueWrapper into JSON data
 // It returns the JSON data and any errors that occured during the function
 func (wf WatchFilterValueWrapper) MarshalJSON() ([]byte, error) {
+	if wf.AntPatterns != nil {
+		return json.Marshal(wf.AntPatterns)
+	}
+
 	if wf.IsPropertyFilter {
 		return json.Marshal(WatchFilterValue{
 			Key:   wf.Key,
@@ -122,10 +157,14 @@ func (s *WatchesService) GetWatch(ctx context.Context, name string) (*Watch, *ht
 	return watch, resp, err
 }
 
-// Description: Creates a new Watch
+// Description: Creates a new Watch. The watch resources are validated before the request is sent
 // Security:  Requires a valid user with "Manage Watches" permission
 // Usage: client.V2.Watches.CreateWatch(ctx, watch)
 func (s *WatchesService) CreateWatch(ctx context.Context, watch *Watch) (*http.Response, error) {
+	if err := watch.Validate(); err != nil {
+		return nil, err
+	}
+
 	req, err := s.client.NewJSONEncodedRequest("POST", "/api/v2/watches", watch)
 	if err != nil {
 		return nil, err
@@ -134,10 +173,14 @@ func (s *WatchesService) CreateWatch(ctx context.Context, watch *Watch) (*http.R
 	return s.client.Do(ctx, req, nil)
 }
 
-// Description: Updates a Watch.
+// Description: Updates a Watch. The watch resources are validated before the request is sent
 // Security:  Requires a valid user with "Manage Watches" permission
 // Usage: client.V2.Watches.UpdateWatch(ctx, "name", watch)
 func (s *WatchesService) UpdateWatch(ctx context.Context, name string, watch *Watch) (*http.Response, error) {
+	if err := watch.Validate(); err != nil {
+		return nil, err
+	}
+
 	path := fmt.Sprintf("/api/v2/watches/%s", name)
 	req, err := s.client.NewJSONEncodedRequest("PUT", path, watch)
 	if err != nil {
@@ -159,3 +202,50 @@ func (s *WatchesService) DeleteWatch(ctx context.Context, name string) (*http.Re
 
 	return s.client.Do(ctx, req, nil)
 }
+
+type ApplyWatchDateRange struct {
+	StartDate *string `json:"start_date,omitempty"`
+	EndDate   *string `json:"end_date,omitempty"`
+}
+
+type ApplyWatchInput struct {
+	WatchNames   *[]string            `json:"watch_names,omitempty"`
+	DateRange    *ApplyWatchDateRange `json:"date_range,omitempty"`
+	Repositories *[]string            `json:"repositories,omitempty"`
+	Builds       *[]string            `json:"builds,omitempty"`
+}
+
+type ApplyWatchOutput struct {
+	Info *string `json:"info,omitempty"`
+}
+
+// NewApplyWatchDateRange creates a date range covering artifacts indexed between start and end
+// A zero end leaves the range open up to now
+func NewApplyWatchDateRange(start time.Time, end time.Time) *ApplyWatchDateRange {
+	r := &ApplyWatchDateRange{
+		StartDate: String(start.Format(time.RFC3339)),
+	}
+
+	if !end.IsZero() {
+		r.EndDate = String(end.Format(time.RFC3339))
+	}
+
+	return r
+}
+
+// Description: Applies watches to the existing content indexed within the date range, optionally limited to some
+// repositories or builds, so violations are generated for historical data
+// Security:  Requires a valid user with "Manage Watches" permission
+// Usage: client.V2.Watches.ApplyWatch(ctx, applyWatchInput)
+func (s *WatchesService) ApplyWatch(ctx context.Context, applyWatchInput *ApplyWatchInput) (*ApplyWatchOutput, *http.Response, error) {
+	req, err := s.client.NewJSONEncodedRequest("POST", "/api/v1/applyWatch", applyWatchInput)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	output := new(ApplyWatchOutput)
+	resp, err := s.client.Do(ctx, req, &output)
+	return output, resp, err
+}
@@ -1,8 +1,17 @@
 package v2
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/atlassian/go-artifactory/v2/artifactory/client"
 )
 
 func TestWatchFilterValueWrapperTestUnmarshalJSON_stringValue(t *testing.T) {
@@ -104,3 +113,106 @@ func TestWatchFilterValueWrapperTestMarshalJSON_objectValue(t *testing.T) {
 		t.Errorf("Expected value to equal %s but got: %s", v, *result.Value)
 	}
 }
+
+func TestWatchFilterValueWrapperTestUnmarshalJSON_antPatternsValue(t *testing.T) {
+	blob := `{"IncludePatterns": ["release-*"], "ExcludePatterns": ["release-snapshot"]}`
+
+	var value WatchFilterValueWrapper
+	if err := json.Unmarshal([]byte(blob), &value); err != nil {
+		t.Errorf("Got the following error: %s", err.Error())
+	}
+
+	if value.AntPatterns == nil {
+		t.Fatalf("Expected AntPatterns to be set but got nil")
+	}
+
+	if (*value.AntPatterns.IncludePatterns)[0] != "release-*" {
+		t.Errorf("Expected include pattern to be 'release-*' but got: %s", (*value.AntPatterns.IncludePatterns)[0])
+	}
+
+	if (*value.AntPatterns.ExcludePatterns)[0] != "release-snapshot" {
+		t.Errorf("Expected exclude pattern to be 'release-snapshot' but got: %s", (*value.AntPatterns.ExcludePatterns)[0])
+	}
+
+	if value.IsPropertyFilter {
+		t.Errorf("Expected IsPropertyFilter to be false but got true")
+	}
+}
+
+func TestWatchFilterValueWrapperTestUnmarshalJSON_reused(t *testing.T) {
+	var value WatchFilterValueWrapper
+	if err := json.Unmarshal([]byte(`{"key": "prop_key","value": "prop_value"}`), &value); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if err := json.Unmarshal([]byte(`{"IncludePatterns": ["release-*"]}`), &value); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if value.Key != nil || value.Value != nil || value.IsPropertyFilter {
+		t.Errorf("Expected the property value to be cleared but got: %+v", value)
+	}
+
+	if err := json.Unmarshal([]byte(`"application/deb"`), &value); err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if value.AntPatterns != nil {
+		t.Errorf("Expected AntPatterns to be cleared but got: %+v", value.AntPatterns)
+	}
+}
+
+func TestWatchFilterValueWrapperTestMarshalJSON_antPatternsValue(t *testing.T) {
+	include := []string{"release-*"}
+
+	value := WatchFilterValueWrapper{
+		AntPatterns: &WatchFilterAntPatterns{
+			IncludePatterns: &include,
+		},
+	}
+
+	result, err := json.Marshal(value)
+
+	if err != nil {
+		t.Errorf("Got the following error: %s", err.Error())
+	}
+
+	if string(result) != `{"IncludePatterns":["release-*"]}` {
+		t.Errorf("Expected result to be the include patterns object but got: %s", string(result))
+	}
+}
+
+func TestWatchesServiceApplyWatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		if r.Method != "POST" || r.URL.Path != "/api/v1/applyWatch" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		if strings.TrimSpace(string(body)) != `{"watch_names":["prod"],"date_range":{"start_date":"2019-01-01T00:00:00Z"},"repositories":["libs-release-local"]}` {
+			t.Errorf("Unexpected body: %s", string(body))
+		}
+
+		fmt.Fprint(w, `{"info": "History Scan is in progress"}`)
+	}))
+	defer server.Close()
+
+	c, _ := client.NewClient(server.URL, nil)
+	v := NewV2(c)
+
+	input := ApplyWatchInput{
+		WatchNames:   &[]string{"prod"},
+		DateRange:    NewApplyWatchDateRange(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}),
+		Repositories: &[]string{"libs-release-local"},
+	}
+
+	output, _, err := v.Watches.ApplyWatch(context.Background(), &input)
+	if err != nil {
+		t.Fatalf("Got the following error: %s", err.Error())
+	}
+
+	if *output.Info != "History Scan is in progress" {
+		t.Errorf("Expected the info message but got: %s", *output.Info)
+	}
+}
@@ -0,0 +1,166 @@
+// Package webhook receives violation alerts delivered by Xray webhooks
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+)
+
+// DefaultMaxBodyBytes is the largest payload accepted when Handler.MaxBodyBytes is not set
+const DefaultMaxBodyBytes = 10 << 20
+
+// PayloadFunc is called once for every valid delivery
+type PayloadFunc func(ctx context.Context, payload *Payload) error
+
+// IssueFunc is called for every issue of a valid delivery
+type IssueFunc func(ctx context.Context, payload *Payload, issue *Issue) error
+
+// ErrorFunc is called with the callback error that failed a delivery
+type ErrorFunc func(r *http.Request, err error)
+
+// Handler is an http.Handler receiving Xray webhook deliveries. It validates the request, decodes the payload and
+// dispatches it to the configured callbacks. A callback error aborts the dispatch and answers with a 500 so that the
+// delivery is reported as failed, the error itself is passed to OnError and never sent back to the caller
+type Handler struct {
+	// OnPayload is called with the whole payload before the issues are dispatched
+	OnPayload PayloadFunc
+
+	// OnIssue is called for each issue of the payload
+	OnIssue IssueFunc
+
+	// OnError is called when a callback fails, the error is logged with the standard logger when it is nil
+	OnError ErrorFunc
+
+	// Headers that must be present with the given value, matching the headers configured on the v1.Webhook
+	Headers map[string]string
+
+	// MaxBodyBytes limits the size of a payload, DefaultMaxBodyBytes is used when it is zero
+	MaxBodyBytes int64
+}
+
+// NewHandler creates a Handler calling onIssue for every issue delivered
+func NewHandler(onIssue IssueFunc) *Handler {
+	return &Handler{OnIssue: onIssue}
+}
+
+// ServeHTTP validates, decodes and dispatches a single delivery
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	for name, value := range h.Headers {
+		// The headers usually hold a shared secret, compared in constant time to not leak it through timing
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(name)), []byte(value)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	payload, err := h.decode(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), payload); err != nil {
+		h.error(r, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) error(r *http.Request, err error) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+		return
+	}
+
+	log.Printf("webhook: delivery failed: %s", err.Error())
+}
+
+func (h *Handler) decode(body io.Reader) (*Payload, error) {
+	limit := h.MaxBodyBytes
+	if limit <= 0 {
+		limit = DefaultMaxBodyBytes
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("payload exceeds %d bytes", limit)
+	}
+
+	return Decode(data)
+}
+
+func (h *Handler) dispatch(ctx context.Context, payload *Payload) error {
+	if h.OnPayload != nil {
+		if err := h.OnPayload(ctx, payload); err != nil {
+			return err
+		}
+	}
+
+	if h.OnIssue == nil {
+		return nil
+	}
+
+	for i := range *payload.Issues {
+		if err := h.OnIssue(ctx, payload, &(*payload.Issues)[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decode parses and validates a webhook payload
+// It returns an error if the data is not a violation alert
+func Decode(data []byte) (*Payload, error) {
+	payload := new(Payload)
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %s", err.Error())
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// Validate checks that the payload has a watch name and issues, each with a type and severity
+func (p *Payload) Validate() error {
+	if p.WatchName == nil || *p.WatchName == "" {
+		return fmt.Errorf("invalid payload: watch_name is required")
+	}
+
+	if p.Issues == nil {
+		return fmt.Errorf("invalid payload: issues are required")
+	}
+
+	for i, issue := range *p.Issues {
+		if issue.Type == nil || *issue.Type == "" {
+			return fmt.Errorf("invalid payload: issue %d has no type", i)
+		}
+
+		if issue.Severity == nil || *issue.Severity == "" {
+			return fmt.Errorf("invalid payload: issue %d has no severity", i)
+		}
+	}
+
+	return nil
+}
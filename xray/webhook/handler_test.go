@@ -0,0 +1,122 @@
+package webhook_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/xero-oss/go-xray/xray/webhook"
+	"github.com/xero-oss/go-xray/xray/webhook/webhooktest"
+)
+
+func TestHandlerServeHTTP_dispatchesIssues(t *testing.T) {
+	var issues []string
+	var watch string
+
+	handler := webhook.NewHandler(func(ctx context.Context, payload *webhook.Payload, issue *webhook.Issue) error {
+		watch = *payload.WatchName
+		issues = append(issues, *issue.CVE)
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, webhooktest.NewRequest(webhooktest.NewSecurityPayload("prod")))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 but got: %d", rec.Code)
+	}
+
+	if watch != "prod" {
+		t.Errorf("Expected watch to be 'prod' but got: %s", watch)
+	}
+
+	if len(issues) != 1 || issues[0] != "CVE-2018-16487" {
+		t.Errorf("Expected a single CVE-2018-16487 issue but got: %v", issues)
+	}
+}
+
+func TestHandlerServeHTTP_requiredHeaders(t *testing.T) {
+	handler := &webhook.Handler{Headers: map[string]string{"X-Token": "secret"}}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, webhooktest.NewRequest(webhooktest.NewLicensePayload("prod")))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 but got: %d", rec.Code)
+	}
+
+	req := webhooktest.NewRequest(webhooktest.NewLicensePayload("prod"))
+	req.Header.Set("X-Token", "secret")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 but got: %d", rec.Code)
+	}
+}
+
+func TestHandlerServeHTTP_invalidRequests(t *testing.T) {
+	handler := &webhook.Handler{}
+
+	cases := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{
+			name:   "wrong method",
+			req:    httptest.NewRequest(http.MethodGet, "/", nil),
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "not json",
+			req:    httptest.NewRequest(http.MethodPost, "/", strings.NewReader("not json")),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing watch name",
+			req:    httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"issues": []}`)),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, c.req)
+
+		if rec.Code != c.status {
+			t.Errorf("%s: expected status %d but got: %d", c.name, c.status, rec.Code)
+		}
+	}
+}
+
+func TestHandlerServeHTTP_callbackError(t *testing.T) {
+	var reported error
+	handler := &webhook.Handler{
+		OnPayload: func(ctx context.Context, payload *webhook.Payload) error {
+			return fmt.Errorf("queue unavailable")
+		},
+		OnError: func(r *http.Request, err error) {
+			reported = err
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, webhooktest.NewRequest(webhooktest.NewSecurityPayload("prod")))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 but got: %d", rec.Code)
+	}
+
+	if strings.Contains(rec.Body.String(), "queue unavailable") {
+		t.Errorf("Expected a generic error body but got: %s", rec.Body.String())
+	}
+
+	if reported == nil || reported.Error() != "queue unavailable" {
+		t.Errorf("Expected the callback error to be reported but got: %v", reported)
+	}
+}
@@ -0,0 +1,50 @@
+package webhook
+
+// Issue types sent by Xray in a webhook payload
+const (
+	IssueTypeSecurity = "security"
+	IssueTypeLicense  = "license"
+)
+
+type InfectedFile struct {
+	Name        *string `json:"name,omitempty"`
+	Path        *string `json:"path,omitempty"`
+	SHA256      *string `json:"sha256,omitempty"`
+	Depth       *int    `json:"depth,omitempty"`
+	ParentSHA   *string `json:"parent_sha,omitempty"`
+	DisplayName *string `json:"display_name,omitempty"`
+	PackageType *string `json:"pkg_type,omitempty"`
+}
+
+type ImpactedArtifact struct {
+	Name          *string         `json:"name,omitempty"`
+	DisplayName   *string         `json:"display_name,omitempty"`
+	Path          *string         `json:"path,omitempty"`
+	PackageType   *string         `json:"pkg_type,omitempty"`
+	SHA256        *string         `json:"sha256,omitempty"`
+	SHA1          *string         `json:"sha1,omitempty"`
+	Depth         *int            `json:"depth,omitempty"`
+	ParentSHA     *string         `json:"parent_sha,omitempty"`
+	InfectedFiles *[]InfectedFile `json:"infected_files,omitempty"`
+}
+
+// Issue mirrors v1.BuildScanIssue as it is sent in a webhook delivery
+type Issue struct {
+	Severity          *string             `json:"severity,omitempty"`
+	Type              *string             `json:"type,omitempty"`
+	Provider          *string             `json:"provider,omitempty"`
+	Created           *string             `json:"created,omitempty"`
+	Summary           *string             `json:"summary,omitempty"`
+	Description       *string             `json:"description,omitempty"`
+	CVE               *string             `json:"cve,omitempty"`
+	ImpactedArtifacts *[]ImpactedArtifact `json:"impacted_artifacts,omitempty"`
+}
+
+// Payload is the body of a violation alert delivered to a webhook referenced by v1.PolicyRuleActions.Webhooks
+type Payload struct {
+	Created     *string  `json:"created,omitempty"`
+	TopSeverity *string  `json:"top_severity,omitempty"`
+	WatchName   *string  `json:"watch_name,omitempty"`
+	PolicyName  *string  `json:"policy_name,omitempty"`
+	Issues      *[]Issue `json:"issues,omitempty"`
+}
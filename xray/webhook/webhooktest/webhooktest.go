@@ -0,0 +1,97 @@
+// Package webhooktest provides sample Xray webhook payloads and requests for testing webhook receivers
+package webhooktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/xero-oss/go-xray/xray"
+	"github.com/xero-oss/go-xray/xray/webhook"
+)
+
+// NewSecurityPayload returns a payload with a single high severity vulnerability on an npm artifact
+func NewSecurityPayload(watchName string) *webhook.Payload {
+	return &webhook.Payload{
+		Created:     xray.String("2019-04-02T11:28:46.305Z"),
+		TopSeverity: xray.String("High"),
+		WatchName:   xray.String(watchName),
+		PolicyName:  xray.String("security-policy"),
+		Issues: &[]webhook.Issue{
+			{
+				Severity:    xray.String("High"),
+				Type:        xray.String(webhook.IssueTypeSecurity),
+				Provider:    xray.String("JFrog"),
+				Created:     xray.String("2019-04-02T11:28:46.305Z"),
+				Summary:     xray.String("Prototype pollution in lodash"),
+				Description: xray.String("Versions of lodash before 4.17.11 are vulnerable to prototype pollution"),
+				CVE:         xray.String("CVE-2018-16487"),
+				ImpactedArtifacts: &[]webhook.ImpactedArtifact{
+					newImpactedArtifact("lodash", "npm", "lodash-4.17.10.tgz"),
+				},
+			},
+		},
+	}
+}
+
+// NewLicensePayload returns a payload with a single banned license on a maven artifact
+func NewLicensePayload(watchName string) *webhook.Payload {
+	return &webhook.Payload{
+		Created:     xray.String("2019-04-02T11:28:46.305Z"),
+		TopSeverity: xray.String("Medium"),
+		WatchName:   xray.String(watchName),
+		PolicyName:  xray.String("license-policy"),
+		Issues: &[]webhook.Issue{
+			{
+				Severity:    xray.String("Medium"),
+				Type:        xray.String(webhook.IssueTypeLicense),
+				Provider:    xray.String("JFrog"),
+				Created:     xray.String("2019-04-02T11:28:46.305Z"),
+				Summary:     xray.String("GPL-3.0"),
+				Description: xray.String("GNU General Public License v3.0 is banned"),
+				ImpactedArtifacts: &[]webhook.ImpactedArtifact{
+					newImpactedArtifact("org.foo:bar", "maven", "bar-1.0.jar"),
+				},
+			},
+		},
+	}
+}
+
+func newImpactedArtifact(name string, packageType string, file string) webhook.ImpactedArtifact {
+	return webhook.ImpactedArtifact{
+		Name:        xray.String(file),
+		DisplayName: xray.String(name),
+		Path:        xray.String("default/libs-release-local/" + file),
+		PackageType: xray.String(packageType),
+		SHA256:      xray.String("d2f3a8d2d1a2a8b4f1e9a0c0b5f2e1e3c4d5e6f708192a3b4c5d6e7f8091a2b3"),
+		Depth:       xray.Int(0),
+		InfectedFiles: &[]webhook.InfectedFile{
+			{
+				Name:        xray.String(file),
+				Path:        xray.String(""),
+				SHA256:      xray.String("d2f3a8d2d1a2a8b4f1e9a0c0b5f2e1e3c4d5e6f708192a3b4c5d6e7f8091a2b3"),
+				Depth:       xray.Int(0),
+				DisplayName: xray.String(name),
+				PackageType: xray.String(packageType),
+			},
+		},
+	}
+}
+
+// Marshal encodes the payload the way Xray sends it
+func Marshal(payload *webhook.Payload) []byte {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+// NewRequest returns a POST request delivering the payload, suitable for calling Handler.ServeHTTP directly
+func NewRequest(payload *webhook.Payload) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(Marshal(payload)))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}